@@ -21,31 +21,297 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
+	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/matm/gocov-html/pkg/config"
 	"github.com/matm/gocov-html/pkg/themes"
+	"github.com/rotisserie/eris"
 )
 
+// openInput returns a reader for the coverage data found at path, which can
+// either be a local file or an http(s) URL.
+func openInput(path string, timeout time.Duration, auth string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		return os.Open(path)
+	}
+	req, err := http.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, eris.Wrap(err, "new request")
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, eris.Wrap(err, "fetch coverage data")
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, eris.Errorf("fetch coverage data: %s: unexpected status %q", path, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// writeIndex writes the reports of the inputs, given as [name=]path, and their
+// index to dir. Inputs are named after their file by default.
+func writeIndex(dir string, args []string, opts themes.ReportOptions, timeout time.Duration, auth string) error {
+	inputs := make([]themes.IndexInput, 0, len(args))
+	for _, arg := range args {
+		name, path := "", arg
+		if i := strings.Index(arg, "="); i > 0 && !strings.ContainsAny(arg[:i], "/:") {
+			name, path = arg[:i], arg[i+1:]
+		}
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		in, err := openInput(path, timeout, auth)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		inputs = append(inputs, themes.IndexInput{Name: name, Data: in})
+	}
+	if err := themes.WriteIndex(dir, inputs, opts); err != nil {
+		return err
+	}
+	log.Printf("Wrote the index of %d reports to %s", len(inputs), filepath.Join(dir, "index.html"))
+	return nil
+}
+
+// writeStylesheet writes the default stylesheet of the theme to path, or to
+// stdout if path is "-".
+func writeStylesheet(path string, theme themes.Beautifier) error {
+	if path == "-" {
+		return themes.WriteStylesheet(os.Stdout, theme)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return eris.Wrap(err, "dump css")
+	}
+	if err := themes.WriteStylesheet(f, theme); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return eris.Wrap(err, "dump css")
+	}
+	log.Printf("Wrote the default CSS of the %s theme to %s", theme.Name(), path)
+	return nil
+}
+
+// readAllLimit reads r until EOF, failing if it holds more than max bytes.
+// There is no limit if max < 1.
+func readAllLimit(r io.Reader, max int64) ([]byte, error) {
+	if max < 1 {
+		data, err := ioutil.ReadAll(r)
+		return data, eris.Wrap(err, "read input")
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, eris.Wrap(err, "read input")
+	}
+	if int64(len(data)) > max {
+		return nil, eris.Errorf("read input: more than %d bytes", max)
+	}
+	return data, nil
+}
+
+// configAliases maps the readable names accepted in config files to the
+// single letter flags.
+var configAliases = map[string]string{
+	"theme":      "t",
+	"format":     "f",
+	"stylesheet": "s",
+}
+
+// configActions are the flags that can't be set in config files, since they
+// change what a run does instead of how the report is rendered.
+var configActions = map[string]bool{
+	"config":   true,
+	"d":        true,
+	"dump-css": true,
+	"index":    true,
+	"lt":       true,
+	"serve":    true,
+	"v":        true,
+}
+
+// configPaths are the flags holding the path of a file or directory. Relative
+// paths in config files are relative to the directory of the config file.
+var configPaths = map[string]bool{
+	"baseline":    true,
+	"codeowners":  true,
+	"gate-result": true,
+	"head":        true,
+	"header-file": true,
+	"history":     true,
+	"path-base":   true,
+	"s":           true,
+}
+
+// applyConfig sets the flags of fs from the JSON object of the config file at
+// path, whose keys are flag names, without the dash, or their aliases. Flags
+// already set, on the command line, take precedence over the config file.
+func applyConfig(fs *flag.FlagSet, path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return eris.Wrap(err, "config")
+	}
+	var values map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&values); err != nil {
+		return eris.Wrapf(err, "config %s", path)
+	}
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	// Config keys per flag name.
+	configured := make(map[string]string)
+	for _, key := range keys {
+		name := key
+		if alias, ok := configAliases[key]; ok {
+			name = alias
+		}
+		if fs.Lookup(name) == nil {
+			return eris.Errorf("config %s: unknown option %q", path, key)
+		}
+		if configActions[name] {
+			return eris.Errorf("config %s: option %q is only allowed on the command line", path, key)
+		}
+		if other, ok := configured[name]; ok {
+			return eris.Errorf("config %s: options %q and %q are the same", path, other, key)
+		}
+		configured[name] = key
+		if set[name] {
+			continue
+		}
+		var value string
+		switch v := values[key].(type) {
+		case string:
+			value = v
+		case bool:
+			value = strconv.FormatBool(v)
+		case json.Number:
+			value = v.String()
+		default:
+			return eris.Errorf("config %s: option %q: want a string, a number or a boolean", path, key)
+		}
+		if configPaths[name] && value != "" && !filepath.IsAbs(value) {
+			value = filepath.Join(filepath.Dir(path), value)
+		}
+		if err := fs.Set(name, value); err != nil {
+			return eris.Wrapf(err, "config %s: option %q", path, key)
+		}
+	}
+	return nil
+}
+
 func main() {
 	var r io.Reader
 	log.SetFlags(0)
 
+	configFile := flag.String("config", "", "path to a JSON file setting options, keyed by flag name; flags given on the command line take precedence")
 	css := flag.String("s", "", "path to custom CSS file")
 	showVersion := flag.Bool("v", false, "show program version")
 	showDefaultCSS := flag.Bool("d", false, "output CSS of default theme")
+	dumpCSS := flag.String("dump-css", "", "write the default CSS of the theme to this file (- for stdout), to be edited and used with -s")
 	listThemes := flag.Bool("lt", false, "list available themes")
 	theme := flag.String("t", "golang", "theme to use for rendering")
 	reverseOrder := flag.Bool("r", false, "put lower coverage functions on top")
 	maxCoverage := flag.Uint64("cmax", 100, "only show functions whose coverage is greater than cmax")
 	minCoverage := flag.Uint64("cmin", 0, "only show functions whose coverage is smaller than cmin")
+	minPackageCoverage := flag.Float64("min-coverage", 0, "only show packages whose coverage is greater than or equal to min-coverage")
+	maxPackageCoverage := flag.Float64("max-coverage", 100, "only show packages whose coverage is smaller than or equal to max-coverage")
+	overviewFiltered := flag.Bool("overview-filtered", false, "compute the overview over the packages shown instead of all packages")
+	minHits := flag.Int64("minhits", 1, "number of times a statement must be reached to be covered")
+	inputOrder := flag.Bool("input-order", false, "render packages in their input order instead of sorting them by name")
+	mergeUnion := flag.Bool("union", false, "merge duplicate packages by keeping the highest hit count of statements")
+	format := flag.String("f", themes.FormatHTML, fmt.Sprintf("output format, one of %s", strings.Join(themes.Formats, ", ")))
+	gz := flag.Bool("gz", false, "compress the report with gzip")
+	indexDir := flag.String("index", "", "write the report of every input to this directory, along with an index.html page linking to them")
+	serve := flag.String("serve", "", "serve the report over HTTP on this address, like :8080, instead of writing it to stdout")
+	serveReadTimeout := flag.Duration("serve-read-timeout", 10*time.Second, "maximum duration for reading a request in serve mode")
+	serveWriteTimeout := flag.Duration("serve-write-timeout", time.Minute, "maximum duration for rendering and writing a report in serve mode")
+	serveMaxInput := flag.Int64("serve-max-input", 512<<20, "maximum size in bytes of the coverage data served in serve mode, no limit if 0")
+	serveConcurrency := flag.Int("serve-concurrency", runtime.NumCPU(), "maximum number of reports rendered at the same time in serve mode")
+	symbols := flag.String("symbols", "", "symbols marking covered and uncovered functions in the text format: ascii (default), unicode or a \"covered,uncovered\" pair")
+	markdownCollapsible := flag.Bool("md-collapse", false, "collapse every package of the markdown format, with its coverage as summary")
+	digestCount := flag.Int("top", 0, "number of packages written by the digest format (all if 0)")
+	digestDescending := flag.Bool("top-desc", false, "sort the digest format by decreasing coverage")
+	pathBase := flag.String("path-base", "", "make file paths of machine readable formats relative to this directory")
+	header := flag.Bool("header", false, "add a summary header to non-HTML outputs")
+	headerFile := flag.String("header-file", "", "write the summary header to this file for formats without comments")
+	copyCommand := flag.Bool("copy", false, "render a button to copy the command used to the clipboard")
+	headFile := flag.String("head", "", "path to a trusted HTML snippet added as is to the <head> of the page")
+	baseHref := flag.String("base", "", "URL the report is hosted at, prefixed to links to other pages")
+	preview := flag.Bool("preview", false, "add Open Graph and Twitter Card tags for rich previews of links to the report")
+	previewImage := flag.String("preview-image", "", "URL of the image of link previews, relative to the base URL if not absolute")
+	file := flag.String("file", "", "only report functions of this source file")
+	focus := flag.String("focus", "", "only report functions whose qualified name matches this regular expression")
+	thousandsSep := flag.String("thousands-sep", "", "separator between groups of thousands of statement counts, like \",\"")
+	largeFunctions := flag.Int("large", 0, "report functions with more than this number of statements, whatever their coverage")
+	largeFunctionsOutput := flag.String("large-output", "report", "where to report large functions: report, stderr or both")
+	exported := flag.Bool("exported", false, "also report the coverage of the exported functions of every package")
+	lastModified := flag.Bool("last-modified", false, "render the last modification time of the source files of every package")
+	minimap := flag.Bool("minimap", false, "render a minimap of covered and missed regions next to the source of long functions")
+	history := flag.String("history", "", "directory of NDJSON summaries of previous runs, to render the coverage trend of every package")
+	historyLength := flag.Int("history-length", 10, "number of summaries of previous runs to render in coverage trends")
+	gauge := flag.Bool("gauge", false, "render the total coverage as a circular gauge")
+	codeOwners := flag.String("codeowners", "", "path to a CODEOWNERS file, to render the coverage by owner")
+	tabs := flag.Bool("tabs", false, "render every package in its own tab, the first tab being the overview")
+	extensions := flag.Bool("ext", false, "render the coverage of statements by file extension")
+	signatures := flag.Bool("sig", false, "show the signature of functions, read from source files")
+	skipEmpty := flag.Bool("skip-empty", false, "leave functions without statements out of function counts")
+	notApplicable := flag.String("na", "", "regular expression matching files that can't be covered, left out of totals")
+	emptyNotApplicable := flag.Bool("na-empty", false, "files without any statement can't be covered, left out of totals")
+	discardReached := flag.String("discard-reached", "", "regular expression matching the source files whose statements are counted as not reached")
+	helpers := flag.String("helpers", "", "regular expression matching the qualified name or file of helper functions, listed apart and left out of totals")
+	redactPrefix := flag.String("redact", "", "remove this prefix from the file paths shown in the report")
+	redactPaths := flag.Bool("redact-all", false, "only show the base name of files in the report")
+	groupByReceiver := flag.Bool("group-recv", false, "group the functions of every package by receiver type, with their own totals")
+	hideSource := flag.Bool("nosrc", false, "do not include the source code of functions in the report")
+	lazySource := flag.Bool("lazy-src", false, "render the source of functions collapsed, only when expanded, for a lighter page")
+	lazySourceNoScript := flag.Bool("lazy-src-noscript", false, "with -lazy-src, also render the full source for browsers without javascript")
+	gateTotal := flag.Float64("gate", 0, "fail if the total coverage is smaller than gate")
+	gatePackage := flag.Float64("gate-pkg", 0, "fail if the coverage of any package is smaller than gate-pkg")
+	baseline := flag.String("baseline", "", "path to coverage data of a previous run, used by the coverage gate")
+	baselineGit := flag.String("baseline-git", "", "git object holding coverage data of a previous run, like origin/main:coverage.json")
+	gateNewFunctions := flag.Float64("gate-func", 0, "with a baseline, fail if a function is less covered than in the baseline or if a new function's coverage is smaller than gate-func")
+	gateStatements := flag.Bool("gate-stmts", false, "with a baseline, fail if the total number of statements decreased by more than gate-stmts-tolerance percent")
+	gateStatementsTolerance := flag.Float64("gate-stmts-tolerance", 0, "percentage of the statements of the baseline that can be removed without failing -gate-stmts")
+	noFail := flag.Bool("no-fail", false, "evaluate and report the coverage gate but never fail")
+	gateResult := flag.String("gate-result", "", "path to a JSON file describing the outcome of the coverage gate")
+	fetchTimeout := flag.Duration("timeout", 30*time.Second, "timeout when fetching coverage data from a URL")
+	authHeader := flag.String("auth", "", "Authorization header value sent when fetching coverage data from a URL")
 
 	flag.Parse()
+	if *configFile != "" {
+		if err := applyConfig(flag.CommandLine, *configFile); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	if *showVersion {
 		fmt.Printf("Version:      %s\n", config.Version)
@@ -58,8 +324,14 @@ func main() {
 	}
 
 	if *listThemes {
+		width := 10
+		for _, th := range themes.List() {
+			if n := len(th.Name()); n > width {
+				width = n
+			}
+		}
 		for _, th := range themes.List() {
-			fmt.Printf("%-10s -- %s\n", th.Name(), th.Description())
+			fmt.Printf("%-*s -- %s\n", width, th.Name(), th.Description())
 		}
 		return
 	}
@@ -70,6 +342,12 @@ func main() {
 	if *maxCoverage > 100 {
 		*maxCoverage = 100
 	}
+	if *minPackageCoverage > *maxPackageCoverage {
+		log.Fatal("error: empty report if min-coverage > max-coverage, please use a smaller min-coverage value.")
+	}
+	if *indexDir != "" && *serve != "" {
+		log.Fatal("error: -index and -serve can't be used together.")
+	}
 
 	err := themes.Use(*theme)
 	if err != nil {
@@ -77,29 +355,131 @@ func main() {
 	}
 
 	if *showDefaultCSS {
-		fmt.Println(themes.Current().Data().Style)
+		*dumpCSS = "-"
+	}
+	if *dumpCSS != "" {
+		if err := writeStylesheet(*dumpCSS, themes.Current()); err != nil {
+			log.Fatal(err)
+		}
 		return
 	}
 
-	switch flag.NArg() {
-	case 0:
+	switch n := flag.NArg(); {
+	case *indexDir != "":
+		if n == 0 {
+			log.Fatalf("Usage: %s -index <dir> [name=]data.json|URL...\n", os.Args[0])
+		}
+	case n == 0:
 		r = os.Stdin
-	case 1:
-		var err error
-		if r, err = os.Open(flag.Arg(0)); err != nil {
+	case n == 1:
+		in, err := openInput(flag.Arg(0), *fetchTimeout, *authHeader)
+		if err != nil {
 			log.Fatal(err)
 		}
+		defer in.Close()
+		r = in
 	default:
-		log.Fatalf("Usage: %s data.json\n", os.Args[0])
+		log.Fatalf("Usage: %s [data.json|URL]\n", os.Args[0])
+	}
+
+	var headHTML string
+	if *headFile != "" {
+		head, err := ioutil.ReadFile(*headFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		headHTML = string(head)
 	}
 
 	opts := themes.ReportOptions{
-		LowCoverageOnTop: *reverseOrder,
-		Stylesheet:       *css,
-		CoverageMin:      uint8(*minCoverage),
-		CoverageMax:      uint8(*maxCoverage),
+		LowCoverageOnTop:        *reverseOrder,
+		Stylesheet:              *css,
+		CoverageMin:             uint8(*minCoverage),
+		CoverageMax:             uint8(*maxCoverage),
+		PackageCoverageMin:      *minPackageCoverage,
+		PackageCoverageMax:      maxPackageCoverage,
+		OverviewFiltered:        *overviewFiltered,
+		MinHits:                 *minHits,
+		InputOrder:              *inputOrder,
+		MergeUnion:              *mergeUnion,
+		Format:                  *format,
+		Gzip:                    *gz,
+		Symbols:                 *symbols,
+		MarkdownCollapsible:     *markdownCollapsible,
+		DigestCount:             *digestCount,
+		DigestDescending:        *digestDescending,
+		PathBase:                *pathBase,
+		Header:                  *header,
+		HeaderFile:              *headerFile,
+		CopyCommand:             *copyCommand,
+		HeadHTML:                headHTML,
+		BaseHref:                *baseHref,
+		Preview:                 *preview,
+		PreviewImage:            *previewImage,
+		File:                    *file,
+		Focus:                   *focus,
+		ThousandsSeparator:      *thousandsSep,
+		Gauge:                   *gauge,
+		Minimap:                 *minimap,
+		LastModified:            *lastModified,
+		History:                 *history,
+		HistoryLength:           *historyLength,
+		Exported:                *exported,
+		LargeFunctions:          *largeFunctions,
+		LargeFunctionsOutput:    *largeFunctionsOutput,
+		CodeOwners:              *codeOwners,
+		Tabs:                    *tabs,
+		Extensions:              *extensions,
+		Signatures:              *signatures,
+		SkipEmptyFunctions:      *skipEmpty,
+		NotApplicable:           *notApplicable,
+		EmptyFilesNotApplicable: *emptyNotApplicable,
+		DiscardReached:          *discardReached,
+		Helpers:                 *helpers,
+		RedactPrefix:            *redactPrefix,
+		RedactPaths:             *redactPaths,
+		GroupByReceiver:         *groupByReceiver,
+		HideSource:              *hideSource,
+		LazySource:              *lazySource,
+		LazySourceNoScript:      *lazySourceNoScript,
+		GateTotal:               *gateTotal,
+		GatePackage:             *gatePackage,
+		Baseline:                *baseline,
+		BaselineGit:             *baselineGit,
+		GateNewFunctions:        *gateNewFunctions,
+		GateStatements:          *gateStatements,
+		GateStatementsTolerance: *gateStatementsTolerance,
+		GateResult:              *gateResult,
 	}
-	if err := themes.HTMLReportCoverage(r, opts); err != nil {
+	if *serve != "" {
+		data, err := readAllLimit(r, *serveMaxInput)
+		if err != nil {
+			log.Fatal(err)
+		}
+		// Fail early on invalid options or coverage data.
+		err = themes.WriteReportCoverage(ioutil.Discard, bytes.NewReader(data), opts)
+		if err != nil && !eris.Is(err, themes.ErrGateFailed) {
+			log.Fatal(err)
+		}
+		srv := &http.Server{
+			Addr:         *serve,
+			Handler:      themes.LimitConcurrency(themes.Handler(data, opts), *serveConcurrency),
+			ReadTimeout:  *serveReadTimeout,
+			WriteTimeout: *serveWriteTimeout,
+		}
+		log.Printf("Serving the report on %s", *serve)
+		log.Fatal(srv.ListenAndServe())
+	}
+	if *indexDir != "" {
+		err = writeIndex(*indexDir, flag.Args(), opts, *fetchTimeout, *authHeader)
+	} else {
+		err = themes.HTMLReportCoverage(r, opts)
+	}
+	if err != nil {
+		if *noFail && eris.Is(err, themes.ErrGateFailed) {
+			log.Printf("%v (ignored with -no-fail)", err)
+			return
+		}
 		log.Fatal(err)
 	}
 }
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gocov-html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tests := []struct {
+		name string
+		// Command line arguments, then content of the config file.
+		args   []string
+		config string
+		// Values of the flags once the config is applied.
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "defaults",
+			config: `{}`,
+			want:   map[string]string{"t": "golang", "cmax": "100", "exported": "false"},
+		},
+		{
+			name:   "values",
+			config: `{"t": "kit", "cmax": 80, "gate": 72.5, "exported": true}`,
+			want:   map[string]string{"t": "kit", "cmax": "80", "gate": "72.5", "exported": "true"},
+		},
+		{
+			name:   "alias",
+			config: `{"theme": "kit", "format": "digest"}`,
+			want:   map[string]string{"t": "kit", "f": "digest"},
+		},
+		{
+			name:   "command line first",
+			args:   []string{"-t", "golang", "-exported=false"},
+			config: `{"theme": "kit", "cmax": 80, "exported": true}`,
+			want:   map[string]string{"t": "golang", "cmax": "80", "exported": "false"},
+		},
+		{
+			name:   "relative path",
+			config: `{"stylesheet": "ci/report.css", "baseline": "/data/base.json"}`,
+			want:   map[string]string{"s": filepath.Join(dir, "ci", "report.css"), "baseline": "/data/base.json"},
+		},
+		{name: "unknown option", config: `{"nope": 1}`, wantErr: true},
+		{name: "same option twice", config: `{"t": "kit", "theme": "kit"}`, wantErr: true},
+		{name: "invalid type", config: `{"t": ["kit"]}`, wantErr: true},
+		{name: "invalid value", config: `{"cmax": "many"}`, wantErr: true},
+		{name: "invalid json", config: `{`, wantErr: true},
+		{name: "version", config: `{"v": true}`, wantErr: true},
+		{name: "list themes", config: `{"lt": true}`, wantErr: true},
+		{name: "dump css", config: `{"d": true}`, wantErr: true},
+		{name: "serve", config: `{"serve": ":8080"}`, wantErr: true},
+		{name: "config", config: `{"config": "other.json"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("gocov-html", flag.ContinueOnError)
+			fs.String("config", "", "")
+			fs.String("t", "golang", "")
+			fs.String("f", "html", "")
+			fs.String("s", "", "")
+			fs.String("baseline", "", "")
+			fs.String("serve", "", "")
+			fs.Uint64("cmax", 100, "")
+			fs.Float64("gate", 0, "")
+			fs.Bool("exported", false, "")
+			fs.Bool("v", false, "")
+			fs.Bool("lt", false, "")
+			fs.Bool("d", false, "")
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(dir, "config.json")
+			if err := ioutil.WriteFile(path, []byte(tt.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+			err := applyConfig(fs, path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := make(map[string]string)
+			for name := range tt.want {
+				got[name] = fs.Lookup(name).Value.String()
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got flags %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadAllLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		max     int64
+		wantErr bool
+	}{
+		{"no limit", "0123456789", 0, false},
+		{"under the limit", "0123456789", 11, false},
+		{"at the limit", "0123456789", 10, false},
+		{"over the limit", "0123456789", 9, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readAllLimit(strings.NewReader(tt.input), tt.max)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readAllLimit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && string(got) != tt.input {
+				t.Errorf("readAllLimit() = %q, want %q", got, tt.input)
+			}
+		})
+	}
+}
+
+func TestOpenInput(t *testing.T) {
+	const data = `{"Packages": []}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/auth":
+			if req.Header.Get("Authorization") != "Bearer t0k3n" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		case "/missing":
+			http.NotFound(w, req)
+			return
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte(data))
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		timeout time.Duration
+		auth    string
+		wantErr bool
+	}{
+		{"plain", "/cov.json", time.Second, "", false},
+		{"authorization header", "/auth", time.Second, "Bearer t0k3n", false},
+		{"missing authorization", "/auth", time.Second, "", true},
+		{"unexpected status", "/missing", time.Second, "", true},
+		{"timeout", "/slow", 50 * time.Millisecond, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, err := openInput(ts.URL+tt.path, tt.timeout, tt.auth)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("openInput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer in.Close()
+			got, err := ioutil.ReadAll(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != data {
+				t.Errorf("openInput() read %q, want %q", got, data)
+			}
+		})
+	}
+}
@@ -13,23 +13,153 @@ func (t defaultTheme) Data() *templateData {
 		ProjectURL: ProjectURL,
 	}
 	
-	td.Style = "Ym9keSB7CiAgICBiYWNrZ3JvdW5kLWNvbG9yOiAjZmZmOwogICAgZm9udC1mYW1pbHk6ICJIZWx2ZXRpY2EgTmV1ZSIsIEhlbHZldGljYSwgQXJpYWwsIHNhbnMtc2VyaWY7Cn0KCnRhYmxlIHsKICAgIG1hcmdpbi1sZWZ0OiAxMHB4OwogICAgYm9yZGVyLWNvbGxhcHNlOiBjb2xsYXBzZTsKfQoKdGQgewogICAgYmFja2dyb3VuZC1jb2xvcjogI2ZmZjsKICAgIHBhZGRpbmc6IDJweDsKfQoKdGFibGUub3ZlcnZpZXcgdGQgewogICAgcGFkZGluZy1yaWdodDogMjBweDsKfQoKdGQucGVyY2VudCwKdGQubGluZWNvdW50IHsKICAgIHRleHQtYWxpZ246IHJpZ2h0Owp9CgpkaXYucGFja2FnZSwKI3RvdGFsY292IHsKICAgIGNvbG9yOiAjZmZmOwogICAgYmFja2dyb3VuZC1jb2xvcjogIzM3NWVhYjsKICAgIGZvbnQtc2l6ZTogMTZweDsKICAgIGZvbnQtd2VpZ2h0OiBib2xkOwogICAgcGFkZGluZzogMTBweDsKICAgIGJvcmRlci1yYWRpdXM6IDVweCA1cHggNXB4IDVweDsKfQoKZGl2LnBhY2thZ2UsCiN0b3RhbGNvdiB7CiAgICBmbG9hdDogcmlnaHQ7CiAgICByaWdodDogMTBweDsKfQoKI3RvdGFsY292IHsKICAgIHRvcDogMTBweDsKICAgIHBvc2l0aW9uOiByZWxhdGl2ZTsKICAgIGJhY2tncm91bmQtY29sb3I6ICNmZmY7CiAgICBjb2xvcjogIzAwMDsKICAgIGJvcmRlcjogMXB4IHNvbGlkICMzNzVlYWI7CiAgICBjbGVhcjogYm90aDsKfQoKI3N1bW1hcnlXcmFwcGVyIHsKICAgIHBvc2l0aW9uOiBmaXhlZDsKICAgIHRvcDogMTBweDsKICAgIGZsb2F0OiByaWdodDsKICAgIHJpZ2h0OiAxMHB4OwoKfQoKc3Bhbi5wYWNrYWdlVG90YWwgewogICAgZmxvYXQ6IHJpZ2h0OwogICAgY29sb3I6ICMwMDA7Cn0KCiNkb2N0aXRsZSB7CiAgICBiYWNrZ3JvdW5kLWNvbG9yOiAjZmZmOwogICAgZm9udC1zaXplOiAyNHB4OwogICAgbWFyZ2luLXRvcDogMjBweDsKICAgIG1hcmdpbi1sZWZ0OiAxMHB4OwogICAgY29sb3I6ICMzNzVlYWI7CiAgICBmb250LXdlaWdodDogYm9sZDsKfQoKI2Fib3V0IHsKICAgIG1hcmdpbi1sZWZ0OiAxOHB4OwogICAgZm9udC1zaXplOiAxMHB4Owp9CgouZnVuY3RpdGxlLAouZnVuY25hbWUgewogICAgdGV4dC1hbGlnbjogY2VudGVyOwogICAgZm9udC1zaXplOiAyMHB4OwogICAgZm9udC13ZWlnaHQ6IGJvbGQ7CiAgICBjb2xvcjogIzM3NWVhYjsKfQoKLmZ1bmNuYW1lIHsKICAgIHRleHQtYWxpZ246IGxlZnQ7CiAgICBtYXJnaW4tdG9wOiAyMHB4OwogICAgbWFyZ2luLWxlZnQ6IDEwcHg7CiAgICBtYXJnaW4tYm90dG9tOiAyMHB4OwogICAgcGFkZGluZzogMnB4IDVweCA1cHg7CiAgICBiYWNrZ3JvdW5kLWNvbG9yOiAjZTBlYmY1Owp9Cgp0YWJsZS5saXN0aW5nIHsKICAgIG1hcmdpbi1sZWZ0OiAxMHB4Owp9Cgp0YWJsZS5saXN0aW5nIHRkIHsKICAgIHBhZGRpbmc6IDBweDsKICAgIGZvbnQtc2l6ZTogMTJweDsKICAgIGJhY2tncm91bmQtY29sb3I6ICNlZWU7CiAgICB2ZXJ0aWNhbC1hbGlnbjogdG9wOwogICAgcGFkZGluZy1sZWZ0OiAxMHB4OwogICAgYm9yZGVyLWJvdHRvbTogMXB4IHNvbGlkICNmZmY7Cn0KCnRhYmxlLmxpc3RpbmcgdGQ6Zmlyc3QtY2hpbGQgewogICAgdGV4dC1hbGlnbjogcmlnaHQ7CiAgICBmb250LXdlaWdodDogYm9sZDsKICAgIHZlcnRpY2FsLWFsaWduOiBjZW50ZXI7Cn0KCnRhYmxlLmxpc3RpbmcgdHIubWlzcyB0ZCB7CiAgICBiYWNrZ3JvdW5kLWNvbG9yOiAjRkZCQkI4Owp9Cgp0YWJsZS5saXN0aW5nIHRyOmxhc3QtY2hpbGQgdGQgewogICAgZm9udC13ZWlnaHQ6IG5vcm1hbDsKICAgIGNvbG9yOiAjMDAwOwp9Cgp0YWJsZS5saXN0aW5nIHRyOmxhc3QtY2hpbGQgdGQ6Zmlyc3QtY2hpbGQgewogICAgZm9udC13ZWlnaHQ6IGJvbGQ7Cn0KCi5pbmZvIHsKICAgIG1hcmdpbi1sZWZ0OiAxMHB4Owp9CgouaW5mbyBjb2RlIHt9CgpwcmUgewogICAgbWFyZ2luOiAxcHg7Cn0KCnByZS5jbWQgewogICAgYmFja2dyb3VuZC1jb2xvcjogI2U5ZTllOTsKICAgIGJvcmRlci1yYWRpdXM6IDVweCA1cHggNXB4IDVweDsKICAgIHBhZGRpbmc6IDEwcHg7CiAgICBtYXJnaW46IDIwcHg7CiAgICBsaW5lLWhlaWdodDogMThweDsKICAgIGZvbnQtc2l6ZTogMTRweDsKfQoKYSB7CiAgICB0ZXh0LWRlY29yYXRpb246IG5vbmU7CiAgICBjb2xvcjogIzM3NWVhYjsKfQoKYTpob3ZlciB7CiAgICB0ZXh0LWRlY29yYXRpb246IHVuZGVybGluZTsKfQoKcCB7CiAgICBtYXJnaW4tbGVmdDogMTBweDsKfQ=="
+	td.Style = "Ym9keSB7CiAgICBiYWNrZ3JvdW5kLWNvbG9yOiAjZmZmOwogICAgZm9udC1mYW1pbHk6ICJIZWx2ZXRpY2EgTmV1ZSIsIEhlbHZldGljYSwgQXJpYWwsIHNhbnMtc2VyaWY7Cn0KCnRhYmxlIHsKICAgIG1hcmdpbi1sZWZ0OiAxMHB4OwogICAgYm9yZGVyLWNvbGxhcHNlOiBjb2xsYXBzZTsKfQoKdGQgewogICAgYmFja2dyb3VuZC1jb2xvcjogI2ZmZjsKICAgIHBhZGRpbmc6IDJweDsKfQoKdGFibGUub3ZlcnZpZXcgdGQgewogICAgcGFkZGluZy1yaWdodDogMjBweDsKfQoKdGFibGUub3ZlcnZpZXcgdHIuZ3JvdXAgdGggewogICAgdGV4dC1hbGlnbjogbGVmdDsKICAgIHBhZGRpbmctdG9wOiAxMHB4OwogICAgYm9yZGVyLWJvdHRvbTogMXB4IHNvbGlkICNjY2M7Cn0KCnRhYmxlLm92ZXJ2aWV3IHRyLmdyb3VwIHRoLnBlcmNlbnQsCnRhYmxlLm92ZXJ2aWV3IHRyLmdyb3VwIHRoLmxpbmVjb3VudCB7CiAgICB0ZXh0LWFsaWduOiByaWdodDsKfQoKdGQucGVyY2VudCwKdGQubGluZWNvdW50IHsKICAgIHRleHQtYWxpZ246IHJpZ2h0Owp9CgpkaXYucGFja2FnZSwKI3RvdGFsY292IHsKICAgIGNvbG9yOiAjZmZmOwogICAgYmFja2dyb3VuZC1jb2xvcjogIzM3NWVhYjsKICAgIGZvbnQtc2l6ZTogMTZweDsKICAgIGZvbnQtd2VpZ2h0OiBib2xkOwogICAgcGFkZGluZzogMTBweDsKICAgIGJvcmRlci1yYWRpdXM6IDVweCA1cHggNXB4IDVweDsKfQoKZGl2LnBhY2thZ2UsCiN0b3RhbGNvdiB7CiAgICBmbG9hdDogcmlnaHQ7CiAgICByaWdodDogMTBweDsKfQoKI3RvdGFsY292IHsKICAgIHRvcDogMTBweDsKICAgIHBvc2l0aW9uOiByZWxhdGl2ZTsKICAgIGJhY2tncm91bmQtY29sb3I6ICNmZmY7CiAgICBjb2xvcjogIzAwMDsKICAgIGJvcmRlcjogMXB4IHNvbGlkICMzNzVlYWI7CiAgICBjbGVhcjogYm90aDsKfQoKI3N1bW1hcnlXcmFwcGVyIHsKICAgIHBvc2l0aW9uOiBmaXhlZDsKICAgIHRvcDogMTBweDsKICAgIGZsb2F0OiByaWdodDsKICAgIHJpZ2h0OiAxMHB4OwoKfQoKI2dhdGUgewogICAgbWFyZ2luOiAxMHB4OwogICAgcGFkZGluZzogNXB4IDEwcHg7CiAgICBib3JkZXItcmFkaXVzOiA1cHggNXB4IDVweCA1cHg7CiAgICBmb250LXdlaWdodDogYm9sZDsKfQoKI2dhdGUucGFzc2VkIHsKICAgIGJhY2tncm91bmQtY29sb3I6ICNkNGY0ZDQ7Cn0KCiNnYXRlLmZhaWxlZCB7CiAgICBiYWNrZ3JvdW5kLWNvbG9yOiAjRkZCQkI4Owp9CgojZ2F0ZSB1bCB7CiAgICBmb250LXdlaWdodDogbm9ybWFsOwogICAgZm9udC1zaXplOiAxMnB4Owp9CgpzcGFuLnBhY2thZ2VUb3RhbCB7CiAgICBmbG9hdDogcmlnaHQ7CiAgICBjb2xvcjogIzAwMDsKfQoKI2RvY3RpdGxlIHsKICAgIGJhY2tncm91bmQtY29sb3I6ICNmZmY7CiAgICBmb250LXNpemU6IDI0cHg7CiAgICBtYXJnaW4tdG9wOiAyMHB4OwogICAgbWFyZ2luLWxlZnQ6IDEwcHg7CiAgICBjb2xvcjogIzM3NWVhYjsKICAgIGZvbnQtd2VpZ2h0OiBib2xkOwp9CgojYWJvdXQgewogICAgbWFyZ2luLWxlZnQ6IDE4cHg7CiAgICBmb250LXNpemU6IDEwcHg7Cn0KCi5mdW5jdGl0bGUsCi5mdW5jbmFtZSB7CiAgICB0ZXh0LWFsaWduOiBjZW50ZXI7CiAgICBmb250LXNpemU6IDIwcHg7CiAgICBmb250LXdlaWdodDogYm9sZDsKICAgIGNvbG9yOiAjMzc1ZWFiOwp9CgouZnVuY25hbWUgewogICAgdGV4dC1hbGlnbjogbGVmdDsKICAgIG1hcmdpbi10b3A6IDIwcHg7CiAgICBtYXJnaW4tbGVmdDogMTBweDsKICAgIG1hcmdpbi1ib3R0b206IDIwcHg7CiAgICBwYWRkaW5nOiAycHggNXB4IDVweDsKICAgIGJhY2tncm91bmQtY29sb3I6ICNlMGViZjU7Cn0KCmRldGFpbHMuaGl0cyBzdW1tYXJ5IHsKICAgIGN1cnNvcjogcG9pbnRlcjsKICAgIGZvbnQtc2l6ZTogMTJweDsKICAgIGNvbG9yOiAjMzc1ZWFiOwp9CgpkZXRhaWxzLmhpdHMgdGQgewogICAgZm9udC1zaXplOiAxMnB4OwogICAgYmFja2dyb3VuZC1jb2xvcjogI2VlZTsKfQoKZGV0YWlscy5oaXRzIHRyLm1pc3MgdGQgewogICAgYmFja2dyb3VuZC1jb2xvcjogI0ZGQkJCODsKfQoKdGFibGUubGlzdGluZyB7CiAgICBtYXJnaW4tbGVmdDogMTBweDsKfQoKdGFibGUubGlzdGluZyB0ZCB7CiAgICBwYWRkaW5nOiAwcHg7CiAgICBmb250LXNpemU6IDEycHg7CiAgICBiYWNrZ3JvdW5kLWNvbG9yOiAjZWVlOwogICAgdmVydGljYWwtYWxpZ246IHRvcDsKICAgIHBhZGRpbmctbGVmdDogMTBweDsKICAgIGJvcmRlci1ib3R0b206IDFweCBzb2xpZCAjZmZmOwp9Cgp0YWJsZS5saXN0aW5nIHRkOmZpcnN0LWNoaWxkIHsKICAgIHRleHQtYWxpZ246IHJpZ2h0OwogICAgZm9udC13ZWlnaHQ6IGJvbGQ7CiAgICB2ZXJ0aWNhbC1hbGlnbjogY2VudGVyOwp9Cgp0YWJsZS5saXN0aW5nIHRyLm1pc3MgdGQgewogICAgYmFja2dyb3VuZC1jb2xvcjogI0ZGQkJCODsKfQoKdGFibGUubGlzdGluZyB0cjpsYXN0LWNoaWxkIHRkIHsKICAgIGZvbnQtd2VpZ2h0OiBub3JtYWw7CiAgICBjb2xvcjogIzAwMDsKfQoKdGFibGUubGlzdGluZyB0cjpsYXN0LWNoaWxkIHRkOmZpcnN0LWNoaWxkIHsKICAgIGZvbnQtd2VpZ2h0OiBib2xkOwp9CgouaW5mbyB7CiAgICBtYXJnaW4tbGVmdDogMTBweDsKfQoKLmluZm8gY29kZSB7fQoKcHJlIHsKICAgIG1hcmdpbjogMXB4Owp9CgpwcmUuY21kIHsKICAgIGJhY2tncm91bmQtY29sb3I6ICNlOWU5ZTk7CiAgICBib3JkZXItcmFkaXVzOiA1cHggNXB4IDVweCA1cHg7CiAgICBwYWRkaW5nOiAxMHB4OwogICAgbWFyZ2luOiAyMHB4OwogICAgbGluZS1oZWlnaHQ6IDE4cHg7CiAgICBmb250LXNpemU6IDE0cHg7Cn0KCmJ1dHRvbi5jb3B5IHsKICAgIG1hcmdpbi1sZWZ0OiAyMHB4OwogICAgbWFyZ2luLXRvcDogLTEwcHg7CiAgICBjb2xvcjogI2ZmZjsKICAgIGJhY2tncm91bmQtY29sb3I6ICMzNzVlYWI7CiAgICBib3JkZXI6IG5vbmU7CiAgICBib3JkZXItcmFkaXVzOiA1cHggNXB4IDVweCA1cHg7CiAgICBwYWRkaW5nOiA1cHggMTBweDsKICAgIGN1cnNvcjogcG9pbnRlcjsKfQoKYSB7CiAgICB0ZXh0LWRlY29yYXRpb246IG5vbmU7CiAgICBjb2xvcjogIzM3NWVhYjsKfQoKYTpob3ZlciB7CiAgICB0ZXh0LWRlY29yYXRpb246IHVuZGVybGluZTsKfQoKcCB7CiAgICBtYXJnaW4tbGVmdDogMTBweDsKfQojZ2F1Z2UgewogICAgbWFyZ2luOiAxMHB4Owp9CgoudHJlbmQgewogICAgY29sb3I6ICMzNzVlYWI7Cn0KCi50cmVuZCBzdmcgewogICAgdmVydGljYWwtYWxpZ246IG1pZGRsZTsKfQoKZGV0YWlscy5oZWxwZXJzIHsKICAgIG1hcmdpbjogMTBweDsKfQoKZGV0YWlscy5sYXp5c291cmNlIHsKICAgIG1hcmdpbjogMTBweDsKfQoKZGV0YWlscy5sYXp5c291cmNlIHN1bW1hcnkgewogICAgY3Vyc29yOiBwb2ludGVyOwogICAgY29sb3I6ICMzNzVlYWI7Cn0KCmRldGFpbHMuaGVscGVycyBzdW1tYXJ5IHsKICAgIGN1cnNvcjogcG9pbnRlcjsKICAgIGNvbG9yOiAjMzc1ZWFiOwp9CgojdGFicyB7CiAgICBsaXN0LXN0eWxlOiBub25lOwogICAgbWFyZ2luOiAxMHB4OwogICAgcGFkZGluZzogMDsKfQoKI3RhYnMgbGkgewogICAgZGlzcGxheTogaW5saW5lLWJsb2NrOwogICAgbWFyZ2luOiAwIDVweCA1cHggMDsKfQoKI3RhYnMgYSB7CiAgICBkaXNwbGF5OiBibG9jazsKICAgIHBhZGRpbmc6IDVweCAxMHB4OwogICAgYmFja2dyb3VuZC1jb2xvcjogI2UwZWJmNTsKICAgIGJvcmRlci1yYWRpdXM6IDVweCA1cHggMCAwOwp9CgojdGFicyBhLmFjdGl2ZSB7CiAgICBjb2xvcjogI2ZmZjsKICAgIGJhY2tncm91bmQtY29sb3I6ICMzNzVlYWI7Cn0KCmJvZHkudGFiYmVkIC50YWIgewogICAgZGlzcGxheTogbm9uZTsKfQoKYm9keS50YWJiZWQgLnRhYi5hY3RpdmUgewogICAgZGlzcGxheTogYmxvY2s7Cn0KCi5taW5pbWFwcGVkIHsKICAgIGRpc3BsYXk6IGZsZXg7CiAgICBhbGlnbi1pdGVtczogc3RyZXRjaDsKfQoKLm1pbmltYXAgewogICAgZmxleDogbm9uZTsKICAgIG1hcmdpbi1sZWZ0OiA1cHg7Cn0KCi5taW5pbWFwIHN2ZyB7CiAgICBkaXNwbGF5OiBibG9jazsKfQoKdGFibGUuc29ydGFibGUgdGggewogICAgY3Vyc29yOiBwb2ludGVyOwogICAgdGV4dC1hbGlnbjogbGVmdDsKfQo="
 	
 	
 	return td
 }
 
 func (t defaultTheme) Template() *template.Template {
-	tmpl := `{{define "theme"}}
+	tmpl := `{{define "funcname"}}{{html .Label}}{{end}}
+{{define "package"}}{{$rp := .Package}}
+        {{if $.Tabs}}<div class="tab" id="tab_pkg_{{html $rp.Pkg.Name}}">{{end}}
+        <div id="pkg_{{html $rp.Pkg.Name}}" class="funcname">
+            Package Overview: {{html $rp.Pkg.Name}}
+            <span class="packageTotal">{{printf "%.1f%%" $rp.PercentageReached}}</span>
+            {{if $rp.Trend}}<span class="trend">{{$rp.Trend}}</span>{{end}}
+        </div>
+        <p>
+            {{$.Count $rp.CoveredFunctions}}/{{$.Count $rp.TotalFunctions}} functions fully covered,
+            {{printf "%.1f%%" $rp.AverageFunctionCoverage}} average function coverage{{if $.SkipEmptyFunctions}} (functions without statements excluded){{end}}.
+            {{with $rp.Exported}}Exported functions: {{printf "%.1f%%" .PercentageReached}} ({{$.Count .ReachedStatements}}/{{$.Count .TotalStatements}} statements).{{end}}
+            {{if and $.LastModified (not $rp.Modified.IsZero)}}Last modified on {{$rp.Modified.Format "2006-01-02 15:04"}}.{{end}}
+        </p>
+        <p>Please select a function to see what's left for testing.</p>
+
+        <table class="overview">
+        {{range $g := $rp.FunctionGroups}}
+        {{if $rp.Groups}}
+            <tr class="group">
+                <th colspan="2"><code>{{html $g.Label}}</code></th>
+                <th class="percent"><code>{{printf "%.1f%%" $g.PercentageReached}}</code></th>
+                <th class="linecount"><code>{{$.Count $g.ReachedStatements}}/{{$.Count $g.TotalStatements}}</code></th>
+                <th></th>
+            </tr>
+        {{end}}
+        {{range $k,$f := $g.Functions}}
+            <tr id="s_fn_{{html $f.Anchor}}">
+                <td>
+                    <code>{{if $.HideSource}}{{template "funcname" $f}}{{else}}<a href="#fn_{{html $f.Anchor}}">{{template "funcname" $f}}</a>{{end}}</code>
+                </td>
+                <td>
+                    <code>{{html $rp.Pkg.Name}}/{{html $f.ShortFileName}}</code>
+                </td>
+                <td class="percent">
+                    <code>{{if $f.NotApplicable}}n/a{{else}}{{printf "%.1f%%" $f.CoveragePercent}}{{end}}</code>
+                </td>
+                <td class="linecount">
+                    <code>{{$.Count $f.StatementsReached}}/{{$.Count (len $f.Statements)}}</code>
+                </td>
+                <td>
+                    {{if $f.Statements}}
+                    <details class="hits">
+                        <summary>hits</summary>
+                        <table>
+                        {{range $i,$h := $f.StatementHits}}
+                            <tr{{if not $h.Covered}} class="miss"{{end}}>
+                                <td class="linecount"><code>{{if $h.LineNumber}}L{{$h.LineNumber}}{{else}}#{{$i}}{{end}}</code></td>
+                                <td class="linecount"><code>{{$h.Reached}}</code></td>
+                            </tr>
+                        {{end}}
+                        </table>
+                    </details>
+                    {{end}}
+                </td>
+            </tr>
+        {{end}}
+        {{end}}
+        </table>
+        {{with $rp.Helpers}}
+        <details class="helpers">
+            <summary>Helpers: {{printf "%.1f%%" .PercentageReached}} ({{$.Count .ReachedStatements}}/{{$.Count .TotalStatements}} statements), left out of the package coverage</summary>
+            <table class="overview">
+            {{range $k,$f := .Functions}}
+                <tr>
+                    <td><code>{{template "funcname" $f}}</code></td>
+                    <td><code>{{html $rp.Pkg.Name}}/{{html $f.ShortFileName}}</code></td>
+                    <td class="percent"><code>{{if $f.NotApplicable}}n/a{{else}}{{printf "%.1f%%" $f.CoveragePercent}}{{end}}</code></td>
+                    <td class="linecount"><code>{{$.Count $f.StatementsReached}}/{{$.Count (len $f.Statements)}}</code></td>
+                </tr>
+            {{end}}
+            </table>
+        </details>
+        {{end}}
+
+        {{/* Functions source code here */}}
+        {{if not $.HideSource}}
+        {{range $k,$f := $rp.Functions}}
+        <div class="funcname" id="fn_{{html $f.Anchor}}">{{if not $f.IsLiteral}}func {{end}}{{template "funcname" $f}}</div>
+        <div class="info">
+            <a href="#s_fn_{{html $f.Anchor}}">Back</a>
+            <p>In <code>{{html $f.DisplayFile}}</code>:</p>
+        </div>
+        {{if $.LazySource}}
+        <details class="lazysource">
+            <summary>Source</summary>
+            <script type="application/json">{{$f.LinesJSON}}</script>
+        </details>
+        {{end}}
+        {{if or (not $.LazySource) $.LazySourceNoScript}}
+        {{if $.LazySource}}<noscript>{{end}}
+        {{$minimap := ""}}{{if $.Minimap}}{{$minimap = $f.Minimap}}{{end}}
+        {{if $minimap}}<div class="minimapped">{{end}}
+        <table class="listing">
+            {{range $p,$info := $f.Lines}}
+            <tr{{if $minimap}} id="fn_{{html $f.Anchor}}_L{{$info.LineNumber}}"{{end}}{{if $info.Missed}} class="miss"{{end}}>
+                <td>{{$info.LineNumber}}</td>
+                <td>
+                    <code><pre>{{$info.Code}}</pre></code>
+                </td>
+            </tr>
+            {{end}}
+        </table>
+        {{if $minimap}}<div class="minimap">{{$minimap}}</div>
+        </div>{{end}}
+        {{if $.LazySource}}</noscript>{{end}}
+        {{end}}
+        {{end}} {{/* range function lines */}}
+        {{end}} {{/* if not HideSource end */}}
+
+        <!--    Can be parsed by external script
+                PACKAGE:{{$rp.CommentName}} DONE:{{printf "%.1f" $rp.PercentageReached}}
+        -->
+        {{if $.Tabs}}</div>{{end}}
+{{end}}
+{{define "theme"}}
 <html>
 	<head>
 		<title>Coverage Report</title>
 		<meta charset="utf-8" />
+        {{with .Preview}}
+        <meta name="description" content="{{html .Description}}" />
+        <meta property="og:type" content="website" />
+        <meta property="og:title" content="{{html .Title}}" />
+        <meta property="og:description" content="{{html .Description}}" />
+        {{if .URL}}<meta property="og:url" content="{{html .URL}}" />{{end}}
+        {{if .Image}}<meta property="og:image" content="{{html .Image}}" />{{end}}
+        <meta name="twitter:card" content="{{.Card}}" />
+        <meta name="twitter:title" content="{{html .Title}}" />
+        <meta name="twitter:description" content="{{html .Description}}" />
+        {{if .Image}}<meta name="twitter:image" content="{{html .Image}}" />{{end}}
+        {{end}}
         {{if .Style}}
         <style type="text/css">
         {{.Style}}
         </style>
         {{end}}
+        {{if .HeadHTML}}
+        {{.HeadHTML}}
+        {{end}}
 	</head>
 	<body>
 		<div id="doctitle">Coverage Report</div>
@@ -37,85 +167,274 @@ func (t defaultTheme) Template() *template.Template {
 		<p>no test files in package.</p>"
         {{else}}
         <div id="about">Generated on {{.When}} with <a href="{{.ProjectURL}}">gocov-html</a></div>
+        {{if .Gauge}}
+        <div id="gauge">{{.Gauge}}</div>
+        {{end}}
+        {{if .Tabs}}
+        <ul id="tabs">
+            <li><a href="#tab_overview">Overview</a></li>
+            {{range $k,$rp := .Packages}}
+            <li><a href="#tab_pkg_{{html $rp.Pkg.Name}}">{{html $rp.Pkg.Name}}</a></li>
+            {{end}}
+        </ul>
+        <div class="tab" id="tab_overview">
+        {{end}}
+        {{if .Gate}}
+        <div id="gate" class="{{if .Gate.Passed}}passed{{else}}failed{{end}}">
+            Coverage gate {{if .Gate.Passed}}passed{{else}}failed{{end}} ({{len .Gate.Rules}} rules)
+            {{if not .Gate.Passed}}
+            <ul>
+            {{range $k,$rule := .Gate.Failed}}
+                <li><code>{{html $rule.String}}</code></li>
+            {{end}}
+            </ul>
+            {{end}}
+        </div>
+        {{end}}
         {{/* Report overview/summary available? */}}
         {{if .Overview}}
         <div class="funcname">Report Overview</div>
-            <table class="overview">
+            <table class="overview{{if .LastModified}} sortable{{end}}">
+            {{if .LastModified}}
+            <thead>
+            <tr>
+                <th>Package</th>
+                <th>Coverage</th>
+                {{if .Trend}}<th>Trend</th>{{end}}
+                <th>Statements</th>
+                {{if .Overview.Exported}}<th>Exported</th>{{end}}
+                <th>Last modified</th>
+            </tr>
+            </thead>
+            {{end}}
+            <tbody>
             {{range $k,$rp := .Packages}}
-            <tr id="s_pkg_{{$rp.Pkg.Name}}">
-                <td><code><a href="#pkg_{{$rp.Pkg.Name}}">{{$rp.Pkg.Name}}</a></code></td>
-                <td class="percent"><code>{{printf "%.1f%%" $rp.PercentageReached}}</code></td>
-                <td class="linecount"><code>{{printf "%d" $rp.ReachedStatements}}/{{printf "%d" $rp.TotalStatements}}</code></td>
+            <tr id="s_pkg_{{html $rp.Pkg.Name}}">
+                <td><code><a href="#pkg_{{html $rp.Pkg.Name}}">{{html $rp.Pkg.Name}}</a></code></td>
+                <td class="percent" data-sort="{{printf "%.4f" $rp.PercentageReached}}"><code>{{printf "%.1f%%" $rp.PercentageReached}}</code></td>
+                {{if $.Trend}}<td class="trend">{{$rp.Trend}}</td>{{end}}
+                <td class="linecount" data-sort="{{$rp.TotalStatements}}"><code>{{$.Count $rp.ReachedStatements}}/{{$.Count $rp.TotalStatements}}</code></td>
+                {{with $rp.Exported}}
+                <td class="percent" data-sort="{{printf "%.4f" .PercentageReached}}"><code>{{printf "%.1f%%" .PercentageReached}} exported</code></td>
+                {{end}}
+                {{if $.LastModified}}
+                <td class="linecount" data-sort="{{if not $rp.Modified.IsZero}}{{$rp.Modified.Unix}}{{else}}0{{end}}"><code>{{if $rp.Modified.IsZero}}unknown{{else}}{{$rp.Modified.Format "2006-01-02 15:04"}}{{end}}</code></td>
+                {{end}}
             </tr>
             {{end}}
+            </tbody>
             </table>
+            {{with .Overview.Exported}}
+            <p>Exported functions of all packages: {{printf "%.1f%%" .PercentageReached}} ({{$.Count .ReachedStatements}}/{{$.Count .TotalStatements}} statements).</p>
+            {{end}}
             <p>
             This coverage report has been generated with the following command:
             </p>
-            <pre class="cmd">{{.Command}}</pre>
-        </div>
+            <pre class="cmd"><code id="cmd">{{html .Command}}</code></pre>
+            {{if .CopyCommand}}
+            <button type="button" class="copy" onclick="copyCommand(this)">Copy</button>
+            <script type="text/javascript">
+            function copyCommand(btn) {
+                var cmd = document.getElementById("cmd").textContent;
+                var done = function() { btn.textContent = "Copied!"; };
+                if (navigator.clipboard) {
+                    navigator.clipboard.writeText(cmd).then(done);
+                    return;
+                }
+                var ta = document.createElement("textarea");
+                ta.value = cmd;
+                document.body.appendChild(ta);
+                ta.select();
+                document.execCommand("copy");
+                document.body.removeChild(ta);
+                done();
+            }
+            </script>
+            {{end}}
         {{end}}
-        {{range $k,$rp := .Packages}}
-        <div id="pkg_{{$rp.Pkg.Name}}" class="funcname">
-            Package Overview: {{$rp.Pkg.Name}}
-            <span class="packageTotal">{{printf "%.1f%%" $rp.PercentageReached}}</span>
-        </div>
-        <p>Please select a function to see what's left for testing.</p>
-
+        {{if .Extensions}}
+        <div class="funcname">Coverage by File Extension</div>
         <table class="overview">
-        {{range $k,$f := $rp.Functions}}
-            <tr id="s_fn_{{$f.Name}}">
-                <td>
-                    <code><a href="#fn_{{$f.Name}}">{{$f.Name}}(...)</a></code>
-                </td>
+        {{range $k,$e := .Extensions}}
+            <tr>
+                <td><code>{{if $e.Extension}}{{html $e.Extension}}{{else}}(none){{end}}</code></td>
+                <td class="percent"><code>{{printf "%.1f%%" $e.PercentageReached}}</code></td>
+                <td class="linecount"><code>{{$.Count $e.ReachedStatements}}/{{$.Count $e.TotalStatements}}</code></td>
+                <td class="linecount"><code>{{$.Count $e.UncoveredStatements}} uncovered</code></td>
+            </tr>
+        {{end}}
+        </table>
+        {{end}}
+        {{if .Owners}}
+        <div class="funcname">Coverage by Owner</div>
+        <table class="overview">
+        {{range $k,$o := .Owners}}
+            <tr>
+                <td><code>{{if $o.Owner}}{{html $o.Owner}}{{else}}(unowned){{end}}</code></td>
+                <td class="percent"><code>{{printf "%.1f%%" $o.PercentageReached}}</code></td>
+                <td class="linecount"><code>{{$.Count $o.ReachedStatements}}/{{$.Count $o.TotalStatements}}</code></td>
+                <td class="linecount"><code>{{$.Count $o.UncoveredStatements}} uncovered</code></td>
+            </tr>
+        {{end}}
+        </table>
+        {{end}}
+        {{if .Focus}}
+        <div class="funcname">Focused Functions</div>
+        <table class="overview">
+        {{range $k,$f := .Focus}}
+            <tr>
+                <td><code>{{html $f.Pkg.Name}}</code></td>
                 <td>
-                    <code>{{$rp.Pkg.Name}}/{{$f.ShortFileName}}</code>
-                </td>
-                <td class="percent">
-                    <code>{{printf "%.1f%%" $f.CoveragePercent}}</code>
-                </td>
-                <td class="linecount">
-                    <code>{{$f.StatementsReached}}/{{len $f.Statements}}</code>
+                    <code>{{if $.HideSource}}{{template "funcname" $f}}{{else}}<a href="#fn_{{html $f.Anchor}}">{{template "funcname" $f}}</a>{{end}}</code>
                 </td>
+                <td class="percent"><code>{{printf "%.1f%%" $f.CoveragePercent}}</code></td>
+                <td class="linecount"><code>{{$.Count $f.StatementsReached}}/{{$.Count (len $f.Statements)}}</code></td>
             </tr>
         {{end}}
         </table>
-
-        {{/* Functions source code here */}}
-        {{range $k,$f := $rp.Functions}}
-        <div class="funcname" id="fn_{{$f.Name}}">func {{$f.Name}}</div>
-        <div class="info">
-            <a href="#s_fn_{{$f.Name}}">Back</a>
-            <p>In <code>{{$f.File}}</code>:</p>
-        </div>
-        <table class="listing">
-            {{range $p,$info := $f.Lines}}
-            <tr{{if $info.Missed}} class="miss"{{end}}>
-                <td>{{$info.LineNumber}}</td>
+        {{end}}
+        {{if .LargeFunctions}}
+        <div class="funcname">Large Functions</div>
+        <p>Functions with more than {{$.Count .LargeFunctionsThreshold}} statements, whatever their coverage. Consider splitting them.</p>
+        <table class="overview">
+        {{range $k,$f := .LargeFunctions}}
+            <tr>
+                <td><code>{{html $f.Pkg.Name}}</code></td>
                 <td>
-                    <code><pre>{{$info.Code}}</pre></code>
+                    <code>{{if $.HideSource}}{{template "funcname" $f}}{{else}}<a href="#fn_{{html $f.Anchor}}">{{template "funcname" $f}}</a>{{end}}</code>
                 </td>
+                <td class="linecount"><code>{{$.Count (len $f.Statements)}} statements</code></td>
+                <td class="percent"><code>{{printf "%.1f%%" $f.CoveragePercent}}</code></td>
             </tr>
-            {{end}}
+        {{end}}
         </table>
-        {{end}} {{/* range function lines */}}
-
-        <!--    Can be parsed by external script
-                PACKAGE:{{$rp.Pkg.Name}} DONE:{{printf "%.1f" $rp.PercentageReached}}
-        -->
+        {{end}}
+        {{if .Tabs}}</div>{{end}}
+        {{range $k,$rp := .Packages}}
+        {{template "package" $.Section $rp}}
         {{end}} {{/* range Packages end */}}
 
         <div id="summaryWrapper">
         {{if not .Overview}}
             {{$rp := index .Packages 0}}
-            <div class="package">{{$rp.Pkg.Name}}</div>
+            <div class="package">{{html $rp.Pkg.Name}}</div>
             <div id="totalcov">{{printf "%.1f%%" $rp.PercentageReached}}</div>
         {{else}}
-            <div class="package">{{.Overview.Pkg.Name}}</div>
+            <div class="package">{{html .Overview.Pkg.Name}}</div>
             <div id="totalcov">{{printf "%.1f%%" .Overview.PercentageReached}}</div>
         {{end}} {{/* if overview end */}}
         </div>
         {{end}} {{/* range if end */}}
+        {{if .Tabs}}
+        <script type="text/javascript">
+        (function() {
+            var tabs = document.querySelectorAll(".tab");
+            var links = document.querySelectorAll("#tabs a");
+            // Shows the tab holding the element with the given id, the first tab otherwise.
+            function show(id) {
+                var el = id ? document.getElementById(id) : null;
+                while (el && !(el.classList && el.classList.contains("tab"))) {
+                    el = el.parentNode;
+                }
+                el = el || tabs[0];
+                for (var i = 0; i < tabs.length; i++) {
+                    tabs[i].classList.toggle("active", tabs[i] === el);
+                }
+                for (var i = 0; i < links.length; i++) {
+                    links[i].classList.toggle("active", links[i].getAttribute("href") === "#" + el.id);
+                }
+            }
+            var hash = function() { return decodeURIComponent(location.hash.slice(1)); };
+            document.body.classList.add("tabbed");
+            window.addEventListener("hashchange", function() { show(hash()); });
+            show(hash());
+        })();
+        </script>
+        {{end}}
+        {{if .LazySource}}
+        <script type="text/javascript">
+        (function() {
+            // Renders the source of a function from the JSON data of its collapsed
+            // listing, the first time it is expanded.
+            function load(details) {
+                var data = details.querySelector("script");
+                if (!data) {
+                    return;
+                }
+                var lines = JSON.parse(data.textContent);
+                var rows = [];
+                for (var i = 0; i < lines.length; i++) {
+                    var l = lines[i];
+                    rows.push("<tr" + (l.missed ? ' class="miss"' : "") + "><td>" + l.line +
+                        "</td><td><code><pre>" + l.code + "</pre></code></td></tr>");
+                }
+                var div = document.createElement("div");
+                div.innerHTML = '<table class="listing">' + rows.join("") + "</table>";
+                details.replaceChild(div.firstChild, data);
+            }
+            var sources = document.querySelectorAll("details.lazysource");
+            for (var i = 0; i < sources.length; i++) {
+                sources[i].addEventListener("toggle", function() {
+                    if (this.open) {
+                        load(this);
+                    }
+                });
+            }
+            // Expands the source of the function linked to, found after its name.
+            function expand() {
+                var el = document.getElementById(decodeURIComponent(location.hash.slice(1)));
+                if (!el || !el.classList.contains("funcname")) {
+                    return;
+                }
+                for (el = el.nextElementSibling; el && !el.classList.contains("funcname"); el = el.nextElementSibling) {
+                    if (el.classList.contains("lazysource")) {
+                        el.open = true;
+                        return;
+                    }
+                }
+            }
+            window.addEventListener("hashchange", expand);
+            expand();
+        })();
+        </script>
+        {{end}}
+        {{if .LastModified}}
+        <script type="text/javascript">
+        (function() {
+            // Sorts the rows of sortable tables by the clicked column, in reverse order
+            // on the next click. Cells with a data-sort attribute are sorted by its
+            // numeric value, others by their text.
+            function sortBy(table, col) {
+                var asc = true;
+                return function() {
+                    var body = table.tBodies[0];
+                    var rows = Array.prototype.slice.call(body.rows);
+                    var key = function(row) {
+                        var td = row.cells[col];
+                        var v = td.getAttribute("data-sort");
+                        return v === null ? td.textContent.trim() : parseFloat(v);
+                    };
+                    rows.sort(function(a, b) {
+                        var ka = key(a), kb = key(b);
+                        var c = ka < kb ? -1 : ka > kb ? 1 : 0;
+                        return asc ? c : -c;
+                    });
+                    asc = !asc;
+                    for (var i = 0; i < rows.length; i++) {
+                        body.appendChild(rows[i]);
+                    }
+                };
+            }
+            var tables = document.querySelectorAll("table.sortable");
+            for (var i = 0; i < tables.length; i++) {
+                var ths = tables[i].tHead.rows[0].cells;
+                for (var j = 0; j < ths.length; j++) {
+                    ths[j].addEventListener("click", sortBy(tables[i], j));
+                }
+            }
+        })();
+        </script>
+        {{end}}
         {{if .Script}}
         <script type="text/javascript">
         {{.Script}}
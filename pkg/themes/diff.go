@@ -0,0 +1,228 @@
+package themes
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/axw/gocov"
+	"github.com/rotisserie/eris"
+)
+
+// loadCoverage reads the JSON coverage data generated by axw/gocov. Packages
+// with the same name are merged.
+func loadCoverage(data []byte) ([]*gocov.Package, error) {
+	packages, err := unmarshalJSON(data)
+	if err != nil {
+		return nil, eris.Wrap(err, "unmarshal coverage data")
+	}
+	r := newReport()
+	for _, pkg := range packages {
+		if err := r.addPackage(pkg); err != nil {
+			return nil, eris.Wrap(err, "merge coverage data")
+		}
+	}
+	return r.packages, nil
+}
+
+// loadCoverageFile reads the JSON coverage data generated by axw/gocov from a
+// file.
+func loadCoverageFile(path string) ([]*gocov.Package, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, eris.Wrap(err, "open coverage file")
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, eris.Wrap(err, "read coverage file")
+	}
+	packages, err := loadCoverage(data)
+	return packages, eris.Wrapf(err, "coverage file %q", path)
+}
+
+// loadCoverageGit reads the JSON coverage data generated by axw/gocov from a
+// git object, like "origin/main:coverage.json". The git command must be
+// available and run from within the repository.
+func loadCoverageGit(object string) ([]*gocov.Package, error) {
+	if !strings.Contains(object, ":") {
+		return nil, eris.Errorf("git object %q: expected <ref>:<path>", object)
+	}
+	if strings.HasPrefix(object, "-") {
+		// Would be read as an option by git.
+		return nil, eris.Errorf("git object %q: must not start with a dash", object)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "show", object)
+	cmd.Stderr = &stderr
+	data, err := cmd.Output()
+	if err != nil {
+		return nil, eris.Wrapf(err, "git show %s: %s", object, strings.TrimSpace(stderr.String()))
+	}
+	packages, err := loadCoverage(data)
+	return packages, eris.Wrapf(err, "git object %q", object)
+}
+
+// functionDelta is the change of coverage of a function between a baseline and
+// the current report.
+type functionDelta struct {
+	Package string
+	Name    string
+	// Base is the coverage of the function in the baseline. Zero if New.
+	Base float64
+	// Head is the current coverage of the function.
+	Head float64
+	// New is true if the function is not part of the baseline.
+	New bool
+}
+
+// diffFunctions compares the coverage of all the functions of head with the
+// ones of base. Functions only found in base and not applicable functions are
+// ignored.
+func diffFunctions(base, head reportPackageList) []functionDelta {
+	type key struct{ pkg, fn string }
+	baseCov := make(map[key]float64)
+	for _, rp := range base {
+		for _, f := range rp.Functions {
+			baseCov[key{rp.Pkg.Name, f.ID}] = f.CoveragePercent()
+		}
+	}
+	deltas := make([]functionDelta, 0)
+	for _, rp := range head {
+		for _, f := range rp.Functions {
+			if f.NotApplicable {
+				continue
+			}
+			d := functionDelta{Package: rp.Pkg.Name, Name: f.ID, Head: f.CoveragePercent()}
+			if cov, ok := baseCov[key{rp.Pkg.Name, f.ID}]; ok {
+				d.Base = cov
+			} else {
+				d.New = true
+			}
+			deltas = append(deltas, d)
+		}
+	}
+	return deltas
+}
+
+// PackageDelta is the change of statement coverage of a package between two
+// runs.
+type PackageDelta struct {
+	Name string `json:"name"`
+	// Base is the coverage of the package in the base run. Zero if New.
+	Base float64 `json:"base"`
+	// Head is the coverage of the package in the head run. Zero if Removed.
+	Head float64 `json:"head"`
+	// New is true if the package is only part of the head run.
+	New bool `json:"new,omitempty"`
+	// Removed is true if the package is only part of the base run.
+	Removed bool `json:"removed,omitempty"`
+}
+
+// Delta returns the change of coverage, in percentage points.
+func (d PackageDelta) Delta() float64 {
+	return d.Head - d.Base
+}
+
+// CoverageDelta is the change of statement coverage between two runs, as
+// returned by DiffSummary.
+type CoverageDelta struct {
+	// Base is the total coverage of the base run.
+	Base float64 `json:"base"`
+	// Head is the total coverage of the head run.
+	Head float64 `json:"head"`
+	// Packages holds the deltas of all packages, sorted by name.
+	Packages []PackageDelta `json:"packages"`
+	// Improved holds the packages of both runs whose coverage increased, most
+	// improved first.
+	Improved []PackageDelta `json:"improved"`
+	// Regressed holds the packages of both runs whose coverage decreased, most
+	// regressed first.
+	Regressed []PackageDelta `json:"regressed"`
+}
+
+// Delta returns the change of total coverage, in percentage points.
+func (d *CoverageDelta) Delta() float64 {
+	return d.Head - d.Base
+}
+
+// summaryPackages reads the JSON coverage data generated by axw/gocov and
+// builds the packages of a report with default options.
+func summaryPackages(r io.Reader) (reportPackageList, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, eris.Wrap(err, "read coverage data")
+	}
+	packages, err := loadCoverage(data)
+	if err != nil {
+		return nil, err
+	}
+	report := newReport()
+	report.CoverageMax = 100
+	report.packages = packages
+	return buildReportPackages(report), nil
+}
+
+// DiffSummary compares the JSON coverage data generated by axw/gocov of two
+// runs and returns the change of coverage, in total and per package, without
+// rendering any report.
+func DiffSummary(base, head io.Reader) (*CoverageDelta, error) {
+	basePackages, err := summaryPackages(base)
+	if err != nil {
+		return nil, eris.Wrap(err, "base")
+	}
+	headPackages, err := summaryPackages(head)
+	if err != nil {
+		return nil, eris.Wrap(err, "head")
+	}
+	baseTotal, headTotal := overview(basePackages), overview(headPackages)
+	rv := &CoverageDelta{
+		Base:      baseTotal.PercentageReached(),
+		Head:      headTotal.PercentageReached(),
+		Packages:  make([]PackageDelta, 0),
+		Improved:  make([]PackageDelta, 0),
+		Regressed: make([]PackageDelta, 0),
+	}
+	deltas := make(map[string]*PackageDelta)
+	for i := range basePackages {
+		rp := &basePackages[i]
+		deltas[rp.Pkg.Name] = &PackageDelta{Name: rp.Pkg.Name, Base: rp.PercentageReached(), Removed: true}
+	}
+	for i := range headPackages {
+		rp := &headPackages[i]
+		d, ok := deltas[rp.Pkg.Name]
+		if !ok {
+			d = &PackageDelta{Name: rp.Pkg.Name, New: true}
+			deltas[rp.Pkg.Name] = d
+		}
+		d.Head = rp.PercentageReached()
+		d.Removed = false
+	}
+	for _, d := range deltas {
+		rv.Packages = append(rv.Packages, *d)
+	}
+	sort.Slice(rv.Packages, func(i, j int) bool {
+		return rv.Packages[i].Name < rv.Packages[j].Name
+	})
+	for _, d := range rv.Packages {
+		switch {
+		case d.New || d.Removed:
+		case d.Delta() > 0:
+			rv.Improved = append(rv.Improved, d)
+		case d.Delta() < 0:
+			rv.Regressed = append(rv.Regressed, d)
+		}
+	}
+	// Packages with the same change are sorted by name.
+	sort.SliceStable(rv.Improved, func(i, j int) bool {
+		return rv.Improved[i].Delta() > rv.Improved[j].Delta()
+	})
+	sort.SliceStable(rv.Regressed, func(i, j int) bool {
+		return rv.Regressed[i].Delta() < rv.Regressed[j].Delta()
+	})
+	return rv, nil
+}
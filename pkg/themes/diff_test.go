@@ -0,0 +1,145 @@
+package themes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/axw/gocov"
+)
+
+func TestDiffFunctions(t *testing.T) {
+	base := []*gocov.Package{{Name: "p", Functions: []*gocov.Function{
+		newFunction("same", 1, 0),
+		newFunction("worse", 1, 1),
+		newFunction("removed", 0),
+	}}}
+	head := []*gocov.Package{{Name: "p", Functions: []*gocov.Function{
+		newFunction("same", 1, 0),
+		newFunction("worse", 1, 0),
+		newFunction("new", 1, 0, 0, 0),
+	}}}
+	opts := ReportOptions{CoverageMax: 100, GateNewFunctions: 50}
+	r := &report{ReportOptions: opts, packages: head, baseline: base}
+
+	deltas := diffFunctions(buildReportPackages(&report{ReportOptions: opts, packages: base}), buildReportPackages(r))
+	want := map[string]functionDelta{
+		"same":  {Package: "p", Name: "same", Base: 50, Head: 50},
+		"worse": {Package: "p", Name: "worse", Base: 100, Head: 50},
+		"new":   {Package: "p", Name: "new", Head: 25, New: true},
+	}
+	if len(deltas) != len(want) {
+		t.Fatalf("got %d deltas, want %d", len(deltas), len(want))
+	}
+	for _, d := range deltas {
+		if d != want[d.Name] {
+			t.Errorf("delta = %+v, want %+v", d, want[d.Name])
+		}
+	}
+
+	gate := evalGate(r, buildReportPackages(r))
+	failed := make(map[string]bool)
+	for _, rule := range gate.Rules {
+		if !rule.Passed {
+			failed[rule.Function] = true
+		}
+	}
+	if len(failed) != 2 || !failed["worse"] || !failed["new"] {
+		t.Errorf("failed functions = %v, want worse and new", failed)
+	}
+}
+
+func TestDiffSummary(t *testing.T) {
+	base := `{"Packages":[
+		{"Name":"same","Functions":[{"Name":"f","Statements":[{"Reached":1},{"Reached":0}]}]},
+		{"Name":"better","Functions":[{"Name":"f","Statements":[{"Reached":0},{"Reached":0}]}]},
+		{"Name":"best","Functions":[{"Name":"f","Statements":[{"Reached":0},{"Reached":0}]}]},
+		{"Name":"worse","Functions":[{"Name":"f","Statements":[{"Reached":1},{"Reached":1}]}]},
+		{"Name":"removed","Functions":[{"Name":"f","Statements":[{"Reached":1}]}]}
+	]}`
+	head := `{"Packages":[
+		{"Name":"same","Functions":[{"Name":"f","Statements":[{"Reached":1},{"Reached":0}]}]},
+		{"Name":"better","Functions":[{"Name":"f","Statements":[{"Reached":1},{"Reached":0}]}]},
+		{"Name":"best","Functions":[{"Name":"f","Statements":[{"Reached":1},{"Reached":1}]}]},
+		{"Name":"worse","Functions":[{"Name":"f","Statements":[{"Reached":1},{"Reached":0}]}]},
+		{"Name":"new","Functions":[{"Name":"f","Statements":[{"Reached":0}]}]}
+	]}`
+	got, err := DiffSummary(strings.NewReader(base), strings.NewReader(head))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Base != 4.0/9*100 || got.Head != 5.0/9*100 {
+		t.Errorf("total = %v -> %v, want %v -> %v", got.Base, got.Head, 4.0/9*100, 5.0/9*100)
+	}
+	names := func(deltas []PackageDelta) string {
+		var rv []string
+		for _, d := range deltas {
+			rv = append(rv, d.Name)
+		}
+		return strings.Join(rv, ",")
+	}
+	if n := names(got.Packages); n != "best,better,new,removed,same,worse" {
+		t.Errorf("packages = %s", n)
+	}
+	if n := names(got.Improved); n != "best,better" {
+		t.Errorf("improved = %s, want best,better", n)
+	}
+	if n := names(got.Regressed); n != "worse" {
+		t.Errorf("regressed = %s, want worse", n)
+	}
+	if d := got.Packages[2]; !d.New || d.Head != 0 {
+		t.Errorf("new package = %+v", d)
+	}
+	if d := got.Packages[3]; !d.Removed || d.Base != 100 {
+		t.Errorf("removed package = %+v", d)
+	}
+
+	if _, err := DiffSummary(strings.NewReader("{"), strings.NewReader(head)); err == nil {
+		t.Error("expected an error for invalid base data")
+	}
+
+	// Packages with the same change are sorted by name, whatever the order of
+	// the map of deltas.
+	data := func(reached string) string {
+		var pkgs []string
+		for _, name := range []string{"z", "a", "m"} {
+			pkgs = append(pkgs, `{"Name":"`+name+`","Functions":[{"Name":"f","Statements":[`+reached+`]}]}`)
+		}
+		return `{"Packages":[` + strings.Join(pkgs, ",") + `]}`
+	}
+	low, high := data(`{"Reached":0},{"Reached":0}`), data(`{"Reached":1},{"Reached":0}`)
+	for i := 0; i < 10; i++ {
+		got, err := DiffSummary(strings.NewReader(low), strings.NewReader(high))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := names(got.Improved); n != "a,m,z" {
+			t.Fatalf("improved = %s, want a,m,z", n)
+		}
+		if got, err = DiffSummary(strings.NewReader(high), strings.NewReader(low)); err != nil {
+			t.Fatal(err)
+		}
+		if n := names(got.Regressed); n != "a,m,z" {
+			t.Fatalf("regressed = %s, want a,m,z", n)
+		}
+	}
+}
+
+func TestLoadCoverageGitOption(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gocov-html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "out")
+	for _, object := range []string{"--output=" + out + ":x", "-p:x"} {
+		if _, err := loadCoverageGit(object); err == nil || !strings.Contains(err.Error(), "dash") {
+			t.Errorf("loadCoverageGit(%q) error = %v, want a leading dash error", object, err)
+		}
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Error("git wrote the output file")
+	}
+}
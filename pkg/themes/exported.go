@@ -0,0 +1,18 @@
+package themes
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// isExported reports whether the function named name by gocov, like "F" or
+// "T.M", belongs to the public API of its package: exported functions and
+// exported methods of exported types. Function literals are never exported.
+func isExported(name string) bool {
+	for _, part := range strings.Split(name, ".") {
+		if !ast.IsExported(strings.Trim(part, "(*)")) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package themes
+
+import (
+	"testing"
+
+	"github.com/axw/gocov"
+)
+
+func TestIsExported(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"Open", true},
+		{"open", false},
+		{"init", false},
+		{"Buffer.String", true},
+		{"Buffer.grow", false},
+		{"buffer.String", false},
+		{"(*Buffer).String", true},
+		{"@15:9", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isExported(tt.name); got != tt.want {
+				t.Errorf("isExported() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportedCoverage(t *testing.T) {
+	r := &report{ReportOptions: ReportOptions{CoverageMax: 100, MinHits: 1, Exported: true}}
+	r.packages = []*gocov.Package{
+		{Name: "a", Functions: []*gocov.Function{
+			newFunction("Open", 1, 1, 0),
+			newFunction("open", 0, 0),
+			newFunction("T.Close", 1),
+		}},
+		{Name: "b", Functions: []*gocov.Function{
+			newFunction("helper", 1),
+		}},
+	}
+	packages := buildReportPackages(r)
+	if got := packages[0].Exported; got == nil || got.ReachedStatements != 3 || got.TotalStatements != 4 {
+		t.Errorf("got exported %+v, want 3/4 statements", got)
+	}
+	if got := packages[1].Exported; got == nil || got.TotalStatements != 0 {
+		t.Errorf("got exported %+v, want no statement", got)
+	}
+	if got := overview(packages).Exported; got == nil || got.ReachedStatements != 3 || got.TotalStatements != 4 {
+		t.Errorf("got overview exported %+v, want 3/4 statements", got)
+	}
+
+	r.Exported = false
+	if got := buildReportPackages(r)[0].Exported; got != nil {
+		t.Errorf("got exported %+v, want nil when not requested", got)
+	}
+}
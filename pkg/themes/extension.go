@@ -0,0 +1,75 @@
+package themes
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// statementCount counts the covered statements of a group of functions.
+type statementCount struct {
+	// TotalStatements is the number of statements counted.
+	TotalStatements int
+	// ReachedStatements is the number of them covered by the tests.
+	ReachedStatements int
+}
+
+// PercentageReached returns the percentage of covered statements.
+func (c *statementCount) PercentageReached() float64 {
+	if c.TotalStatements == 0 {
+		return 0
+	}
+	return float64(c.ReachedStatements) / float64(c.TotalStatements) * 100
+}
+
+// UncoveredStatements returns the number of statements left to cover.
+func (c *statementCount) UncoveredStatements() int {
+	return c.TotalStatements - c.ReachedStatements
+}
+
+// extensionCoverage is the coverage of the statements of all files sharing
+// the same extension.
+type extensionCoverage struct {
+	// Extension is everything from the first dot of the file name, so that
+	// generated files like "api.pb.go" have their own ".pb.go" extension.
+	Extension string
+	statementCount
+}
+
+// fileExtension returns the extension of path, starting at the first dot of
+// the file name, leading dots excepted. Returns an empty string if the file
+// name has no dot.
+func fileExtension(path string) string {
+	name := strings.TrimLeft(filepath.Base(path), ".")
+	i := strings.Index(name, ".")
+	if i < 0 {
+		return ""
+	}
+	return name[i:]
+}
+
+// extensionBreakdown sums the statements of all packages per file extension.
+// Extensions with the most uncovered statements come first.
+func extensionBreakdown(packages reportPackageList) []*extensionCoverage {
+	byExt := make(map[string]*extensionCoverage)
+	for _, rp := range packages {
+		for ext, e := range rp.extensions {
+			if byExt[ext] == nil {
+				byExt[ext] = &extensionCoverage{Extension: ext}
+			}
+			byExt[ext].TotalStatements += e.TotalStatements
+			byExt[ext].ReachedStatements += e.ReachedStatements
+		}
+	}
+	rv := make([]*extensionCoverage, 0, len(byExt))
+	for _, e := range byExt {
+		rv = append(rv, e)
+	}
+	sort.Slice(rv, func(i, j int) bool {
+		if rv[i].UncoveredStatements() != rv[j].UncoveredStatements() {
+			return rv[i].UncoveredStatements() > rv[j].UncoveredStatements()
+		}
+		return rv[i].Extension < rv[j].Extension
+	})
+	return rv
+}
@@ -0,0 +1,57 @@
+package themes
+
+import (
+	"testing"
+
+	"github.com/axw/gocov"
+)
+
+func TestFileExtension(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/src/p/p.go", ".go"},
+		{"/src/p/api.pb.go", ".pb.go"},
+		{"/src/p/zz_generated.deepcopy.go", ".deepcopy.go"},
+		{"/src/p/.hidden.go", ".go"},
+		{"/src/p/Makefile", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := fileExtension(tt.path); got != tt.want {
+				t.Errorf("fileExtension() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtensionBreakdown(t *testing.T) {
+	r := &report{ReportOptions: ReportOptions{CoverageMax: 100, MinHits: 1}}
+	r.packages = []*gocov.Package{
+		{Name: "a", Functions: []*gocov.Function{
+			{Name: "f", File: "a/a.go", Statements: newStatements(1, 1, 0)},
+			{Name: "g", File: "a/a.pb.go", Statements: newStatements(0, 0, 1)},
+		}},
+		{Name: "b", Functions: []*gocov.Function{
+			{Name: "f", File: "b/b.pb.go", Statements: newStatements(0, 0)},
+		}},
+	}
+	packages := buildReportPackages(r)
+	got := extensionBreakdown(packages)
+	if len(got) != 2 {
+		t.Fatalf("got %d extensions, want 2", len(got))
+	}
+	if got[0].Extension != ".pb.go" || got[0].ReachedStatements != 1 || got[0].TotalStatements != 5 {
+		t.Errorf("got %+v, want 1/5 statements of .pb.go first", got[0])
+	}
+	total, reached := 0, 0
+	for _, e := range got {
+		total += e.TotalStatements
+		reached += e.ReachedStatements
+	}
+	ov := overview(packages)
+	if total != ov.TotalStatements || reached != ov.ReachedStatements {
+		t.Errorf("extensions sum to %d/%d, want package totals %d/%d", reached, total, ov.ReachedStatements, ov.TotalStatements)
+	}
+}
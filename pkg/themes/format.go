@@ -0,0 +1,528 @@
+package themes
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"github.com/matm/gocov-html/pkg/config"
+	"github.com/rotisserie/eris"
+)
+
+// Output formats of the report.
+const (
+	// FormatHTML renders the report with the current theme.
+	FormatHTML = "html"
+	// FormatNDJSON writes one JSON object per package, followed by an overview
+	// object for all packages.
+	FormatNDJSON = "ndjson"
+	// FormatEditorMap writes a JSON object mapping source files to their covered
+	// and uncovered lines, for editor integrations.
+	FormatEditorMap = "editor-map"
+	// FormatDigest writes one "NN.N%  package" line per package, sorted by
+	// coverage, worst packages first.
+	FormatDigest = "digest"
+	// FormatSonar writes the line coverage of all source files in the SonarQube
+	// generic coverage XML format.
+	FormatSonar = "sonar"
+	// FormatPrometheus writes the coverage ratio of every package and of the
+	// whole report as Prometheus metrics, in the text exposition format read by
+	// the textfile collector of the node exporter.
+	FormatPrometheus = "prometheus"
+	// FormatText writes a plain text report: the coverage of every function,
+	// marked as covered or not, grouped by package.
+	FormatText = "text"
+	// FormatMarkdown writes a GitHub Flavored Markdown report, like for pull
+	// request comments: the total coverage, then a table of the functions of
+	// every package, optionally collapsed.
+	FormatMarkdown = "markdown"
+)
+
+// Formats lists all supported output formats.
+var Formats = []string{
+	FormatHTML,
+	FormatNDJSON,
+	FormatEditorMap,
+	FormatDigest,
+	FormatSonar,
+	FormatPrometheus,
+	FormatText,
+	FormatMarkdown,
+}
+
+// commentSyntax is the syntax of the comments of an output format: a line of
+// text between a prefix and an optional suffix.
+type commentSyntax struct {
+	prefix, suffix string
+}
+
+// commentSyntaxes holds the comment syntax of output formats supporting
+// comments. Used for the summary header.
+var commentSyntaxes = map[string]commentSyntax{
+	FormatDigest:     {prefix: "#"},
+	FormatPrometheus: {prefix: "#"},
+	FormatText:       {prefix: "#"},
+	FormatMarkdown:   {prefix: "<!--", suffix: "-->"},
+	FormatSonar:      {prefix: "<!--", suffix: "-->"},
+}
+
+func validFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// summaryHeader returns the lines of text describing a report: generation
+// time, tool version and total coverage of the packages listed by the report,
+// once filtered by their coverage.
+func summaryHeader(r *report) []string {
+	listed, _ := r.listedPackages(buildReportPackages(r))
+	ov := overview(listed)
+	return []string{
+		fmt.Sprintf("Generated on %s by gocov-html %s", time.Now().Format(time.RFC1123), config.Version),
+		fmt.Sprintf("Total coverage: %.1f%% (%s/%s statements)", ov.PercentageReached(),
+			formatCount(ov.ReachedStatements, r.ThousandsSeparator), formatCount(ov.TotalStatements, r.ThousandsSeparator)),
+	}
+}
+
+// formatCount formats n with sep inserted between groups of thousands.
+func formatCount(n int, sep string) string {
+	s := strconv.Itoa(n)
+	if sep == "" {
+		return s
+	}
+	sign := ""
+	if n < 0 {
+		sign, s = "-", s[1:]
+	}
+	var b strings.Builder
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(c)
+	}
+	return sign + b.String()
+}
+
+// writeHeader writes the summary header of a non-HTML report, as comments if the
+// output format has a comment syntax, or to the header sidecar file otherwise.
+func writeHeader(w io.Writer, r *report) error {
+	lines := summaryHeader(r)
+	syntax, ok := commentSyntaxes[r.Format]
+	if !ok {
+		if r.HeaderFile == "" {
+			return eris.Errorf("header: %s format has no comment syntax, a header file is required", r.Format)
+		}
+		data := []byte(strings.Join(lines, "\n") + "\n")
+		return eris.Wrap(ioutil.WriteFile(r.HeaderFile, data, 0644), "write header file")
+	}
+	for _, line := range lines {
+		line = syntax.prefix + " " + line
+		if syntax.suffix != "" {
+			line += " " + syntax.suffix
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return eris.Wrap(err, "write header")
+		}
+	}
+	return nil
+}
+
+// writeReport writes the report to w using the output format of the report options.
+func writeReport(w io.Writer, r *report) error {
+	if r.Header && r.Format != FormatHTML {
+		if err := writeHeader(w, r); err != nil {
+			return err
+		}
+	}
+	switch r.Format {
+	case FormatNDJSON:
+		return printNDJSON(w, r)
+	case FormatEditorMap:
+		return printEditorMap(w, r)
+	case FormatDigest:
+		return printDigest(w, r)
+	case FormatSonar:
+		return printSonar(w, r)
+	case FormatPrometheus:
+		return printPrometheus(w, r)
+	case FormatText:
+		return printText(w, r)
+	case FormatMarkdown:
+		return printMarkdown(w, r)
+	default:
+		fmt.Fprintln(w)
+		return printReport(w, r)
+	}
+}
+
+// packageSummary is the coverage summary of a package, used by machine readable
+// formats.
+type packageSummary struct {
+	Name       string  `json:"name"`
+	Reached    int     `json:"reached"`
+	Total      int     `json:"total"`
+	Percentage float64 `json:"percentage"`
+	// Exported is the coverage of the exported functions, if requested.
+	Exported *exportedSummary `json:"exported,omitempty"`
+	// Overview is true for the summary of all packages.
+	Overview bool `json:"overview,omitempty"`
+}
+
+// exportedSummary is the coverage summary of the exported functions of a
+// package.
+type exportedSummary struct {
+	Reached    int     `json:"reached"`
+	Total      int     `json:"total"`
+	Percentage float64 `json:"percentage"`
+}
+
+func newPackageSummary(rp reportPackage) packageSummary {
+	rv := packageSummary{
+		Name:       rp.Pkg.Name,
+		Reached:    rp.ReachedStatements,
+		Total:      rp.TotalStatements,
+		Percentage: rp.PercentageReached(),
+	}
+	if rp.Exported != nil {
+		rv.Exported = &exportedSummary{
+			Reached:    rp.Exported.ReachedStatements,
+			Total:      rp.Exported.TotalStatements,
+			Percentage: rp.Exported.PercentageReached(),
+		}
+	}
+	return rv
+}
+
+// printNDJSON writes one line of JSON per package, then a final line with the
+// overview of all packages.
+func printNDJSON(w io.Writer, r *report) error {
+	enc := json.NewEncoder(w)
+	packages, totals := r.listedPackages(buildReportPackages(r))
+	for _, rp := range packages {
+		if err := enc.Encode(newPackageSummary(rp)); err != nil {
+			return eris.Wrap(err, "encode package")
+		}
+	}
+	ov := newPackageSummary(overview(totals))
+	ov.Overview = true
+	return eris.Wrap(enc.Encode(ov), "encode overview")
+}
+
+// outputPath returns the path of a source file as written in machine readable
+// formats: relative to the PathBase option if set, absolute otherwise. Always
+// uses forward slashes.
+func (r *report) outputPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if r.PathBase != "" {
+		base, err := filepath.Abs(r.PathBase)
+		if err == nil {
+			if rel, err := filepath.Rel(base, path); err == nil {
+				return filepath.ToSlash(rel)
+			}
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// lineCoverage holds the covered and uncovered lines of a source file. A line
+// is covered if any statement starting on it is covered.
+type lineCoverage struct {
+	Covered   []int `json:"covered"`
+	Uncovered []int `json:"uncovered"`
+}
+
+// fileLineCoverage computes the line coverage of all source files of the
+// listed packages, indexed by their output path. All functions of these
+// packages are included, whatever their coverage, so that files are complete:
+// helpers too, as their lines are part of the files. Not applicable functions
+// are ignored.
+func fileLineCoverage(r *report) (map[string]*lineCoverage, error) {
+	// Line coverage status, per file.
+	lines := make(map[string]map[int]bool)
+	listed, _ := r.listedPackages(buildReportPackages(r.unfiltered()))
+	for _, rp := range listed {
+		fns := rp.Functions
+		if rp.Helpers != nil {
+			fns = append(append(reportFunctionList(nil), fns...), rp.Helpers.Functions...)
+		}
+		for _, f := range fns {
+			if f.NotApplicable {
+				continue
+			}
+			src, err := loadSource(f.File)
+			if err != nil {
+				return nil, err
+			}
+			path := r.outputPath(f.File)
+			if lines[path] == nil {
+				lines[path] = make(map[int]bool)
+			}
+			for _, st := range f.Statements {
+				line := src.Line(st.Start)
+				lines[path][line] = lines[path][line] || covered(st, r.MinHits)
+			}
+		}
+	}
+	files := make(map[string]*lineCoverage)
+	for path, status := range lines {
+		lc := &lineCoverage{Covered: make([]int, 0), Uncovered: make([]int, 0)}
+		for line, ok := range status {
+			if ok {
+				lc.Covered = append(lc.Covered, line)
+			} else {
+				lc.Uncovered = append(lc.Uncovered, line)
+			}
+		}
+		sort.Ints(lc.Covered)
+		sort.Ints(lc.Uncovered)
+		files[path] = lc
+	}
+	return files, nil
+}
+
+// printEditorMap writes the covered and uncovered lines of all source files as
+// a JSON object indexed by file path.
+func printEditorMap(w io.Writer, r *report) error {
+	files, err := fileLineCoverage(r)
+	if err != nil {
+		return eris.Wrap(err, "line coverage")
+	}
+	return eris.Wrap(json.NewEncoder(w).Encode(files), "encode editor map")
+}
+
+// sonarCoverage is the root element of the SonarQube generic coverage format.
+type sonarCoverage struct {
+	XMLName xml.Name    `xml:"coverage"`
+	Version int         `xml:"version,attr"`
+	Files   []sonarFile `xml:"file"`
+}
+
+type sonarFile struct {
+	Path  string      `xml:"path,attr"`
+	Lines []sonarLine `xml:"lineToCover"`
+}
+
+type sonarLine struct {
+	LineNumber int  `xml:"lineNumber,attr"`
+	Covered    bool `xml:"covered,attr"`
+}
+
+// printSonar writes the covered and uncovered lines of all source files in the
+// SonarQube generic coverage format, files sorted by path.
+func printSonar(w io.Writer, r *report) error {
+	files, err := fileLineCoverage(r)
+	if err != nil {
+		return eris.Wrap(err, "line coverage")
+	}
+	cov := sonarCoverage{Version: 1, Files: make([]sonarFile, 0, len(files))}
+	for path, lc := range files {
+		sf := sonarFile{Path: path}
+		for _, line := range lc.Covered {
+			sf.Lines = append(sf.Lines, sonarLine{LineNumber: line, Covered: true})
+		}
+		for _, line := range lc.Uncovered {
+			sf.Lines = append(sf.Lines, sonarLine{LineNumber: line})
+		}
+		sort.Slice(sf.Lines, func(i, j int) bool {
+			return sf.Lines[i].LineNumber < sf.Lines[j].LineNumber
+		})
+		cov.Files = append(cov.Files, sf)
+	}
+	sort.Slice(cov.Files, func(i, j int) bool {
+		return cov.Files[i].Path < cov.Files[j].Path
+	})
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(cov); err != nil {
+		return eris.Wrap(err, "encode sonar coverage")
+	}
+	_, err = io.WriteString(w, "\n")
+	return eris.Wrap(err, "encode sonar coverage")
+}
+
+// printDigest writes one line per package with its coverage, sorted by coverage.
+// Only the DigestCount first packages are written, if set.
+func printDigest(w io.Writer, r *report) error {
+	packages, _ := r.listedPackages(buildReportPackages(r))
+	less := func(i, j int) bool {
+		return packages[i].PercentageReached() < packages[j].PercentageReached()
+	}
+	if r.DigestDescending {
+		less = func(i, j int) bool {
+			return packages[i].PercentageReached() > packages[j].PercentageReached()
+		}
+	}
+	sort.SliceStable(packages, less)
+	if r.DigestCount > 0 && r.DigestCount < len(packages) {
+		packages = packages[:r.DigestCount]
+	}
+	for _, rp := range packages {
+		if _, err := fmt.Fprintf(w, "%5.1f%%  %s\n", rp.PercentageReached(), rp.Pkg.Name); err != nil {
+			return eris.Wrap(err, "write digest")
+		}
+	}
+	return nil
+}
+
+// prometheusLabelEscaper escapes label values of the Prometheus text format.
+var prometheusLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// printPrometheus writes the coverage ratio, between 0 and 1, of all listed
+// packages as a gocov_coverage_ratio gauge labelled by package, followed by the
+// total coverage ratio as gocov_total_coverage_ratio.
+func printPrometheus(w io.Writer, r *report) error {
+	packages, totals := r.listedPackages(buildReportPackages(r))
+	var b strings.Builder
+	b.WriteString("# HELP gocov_coverage_ratio Ratio of statements covered by tests, per package.\n")
+	b.WriteString("# TYPE gocov_coverage_ratio gauge\n")
+	for _, rp := range packages {
+		fmt.Fprintf(&b, "gocov_coverage_ratio{package=\"%s\"} %s\n",
+			prometheusLabelEscaper.Replace(rp.Pkg.Name), prometheusRatio(rp))
+	}
+	b.WriteString("# HELP gocov_total_coverage_ratio Ratio of statements covered by tests, all packages included.\n")
+	b.WriteString("# TYPE gocov_total_coverage_ratio gauge\n")
+	fmt.Fprintf(&b, "gocov_total_coverage_ratio %s\n", prometheusRatio(overview(totals)))
+	_, err := io.WriteString(w, b.String())
+	return eris.Wrap(err, "write metrics")
+}
+
+// prometheusRatio formats the coverage ratio of a package as a metric value.
+func prometheusRatio(rp reportPackage) string {
+	return strconv.FormatFloat(rp.PercentageReached()/100, 'g', 4, 64)
+}
+
+// symbolSet holds the glyphs marking covered and uncovered items in text
+// outputs.
+type symbolSet struct {
+	Covered   string
+	Uncovered string
+}
+
+// Predefined symbol sets, by name.
+var symbolSets = map[string]symbolSet{
+	"ascii":   {"+", "-"},
+	"unicode": {"\u2713", "\u2717"},
+}
+
+// parseSymbols returns the symbol set named name, or a custom set given as
+// "covered,uncovered", like "OK,KO". Defaults to ASCII symbols, which display
+// everywhere.
+func parseSymbols(name string) (symbolSet, error) {
+	if name == "" {
+		return symbolSets["ascii"], nil
+	}
+	if s, ok := symbolSets[name]; ok {
+		return s, nil
+	}
+	parts := strings.Split(name, ",")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return symbolSet{}, eris.Errorf("symbols %q: want ascii, unicode or a \"covered,uncovered\" pair", name)
+	}
+	return symbolSet{parts[0], parts[1]}, nil
+}
+
+// printText writes the coverage of every function of the listed packages, one
+// per line, marked with the covered or uncovered symbol, then the total
+// coverage. Not applicable functions are not marked.
+func printText(w io.Writer, r *report) error {
+	symbols, err := parseSymbols(r.Symbols)
+	if err != nil {
+		return err
+	}
+	packages, totals := r.listedPackages(buildReportPackages(r))
+	count := func(n int) string { return formatCount(n, r.ThousandsSeparator) }
+	// Width of the marks, so that function names are aligned.
+	width := utf8.RuneCountInString(symbols.Covered)
+	if n := utf8.RuneCountInString(symbols.Uncovered); n > width {
+		width = n
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, rp := range packages {
+		fmt.Fprintf(tw, "%s\t%.1f%%\t%s/%s\n", rp.Pkg.Name, rp.PercentageReached(),
+			count(rp.ReachedStatements), count(rp.TotalStatements))
+		for _, f := range rp.Functions {
+			mark, percent := symbols.Uncovered, fmt.Sprintf("%.1f%%", f.CoveragePercent())
+			switch {
+			case f.NotApplicable:
+				mark, percent = "", "n/a"
+			case f.StatementsReached == len(f.Statements):
+				mark = symbols.Covered
+			}
+			fmt.Fprintf(tw, "  %-*s %s\t%s\t%s/%s\t%s\n", width, mark, f.Label(), percent,
+				count(f.StatementsReached), count(len(f.Statements)), f.DisplayFile)
+		}
+	}
+	ov := overview(totals)
+	fmt.Fprintf(tw, "Total\t%.1f%%\t%s/%s\n", ov.PercentageReached(),
+		count(ov.ReachedStatements), count(ov.TotalStatements))
+	return eris.Wrap(tw.Flush(), "write text report")
+}
+
+// markdownCellEscaper escapes the text of GitHub Flavored Markdown table
+// cells. Pipes must be escaped even in code spans.
+var markdownCellEscaper = strings.NewReplacer("|", `\|`, "\n", " ")
+
+// markdownCode returns s as a code span of a table cell.
+func markdownCode(s string) string {
+	s = markdownCellEscaper.Replace(s)
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+	return "`" + s + "`"
+}
+
+// printMarkdown writes the total coverage of the listed packages, then the
+// coverage of every function of each package in a table. Packages have a
+// heading, or are collapsed in a details element whose summary is their
+// coverage with the MarkdownCollapsible option.
+func printMarkdown(w io.Writer, r *report) error {
+	packages, totals := r.listedPackages(buildReportPackages(r))
+	count := func(n int) string { return formatCount(n, r.ThousandsSeparator) }
+	ov := overview(totals)
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", previewTitle)
+	fmt.Fprintf(&b, "Total coverage: **%.1f%%** (%s/%s statements) of %s.\n",
+		ov.PercentageReached(), count(ov.ReachedStatements), count(ov.TotalStatements),
+		pluralize(len(totals), "package", r.ThousandsSeparator))
+	for _, rp := range packages {
+		title := fmt.Sprintf("%s \u2014 %.1f%%", rp.Pkg.Name, rp.PercentageReached())
+		if r.MarkdownCollapsible {
+			fmt.Fprintf(&b, "\n<details><summary>%s</summary>\n\n", html.EscapeString(title))
+		} else {
+			fmt.Fprintf(&b, "\n### %s\n\n", title)
+		}
+		b.WriteString("| Function | File | Coverage | Statements |\n")
+		b.WriteString("|:---|:---|---:|---:|\n")
+		for _, f := range rp.Functions {
+			percent := fmt.Sprintf("%.1f%%", f.CoveragePercent())
+			if f.NotApplicable {
+				percent = "n/a"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s/%s |\n", markdownCode(f.Label()),
+				markdownCellEscaper.Replace(f.DisplayFile), percent,
+				count(f.StatementsReached), count(len(f.Statements)))
+		}
+		if r.MarkdownCollapsible {
+			b.WriteString("\n</details>\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return eris.Wrap(err, "write markdown report")
+}
@@ -0,0 +1,380 @@
+package themes
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/axw/gocov"
+)
+
+// writeSource writes a Go source file in a temporary directory. Returns the
+// directory and the path to the file.
+func writeSource(t *testing.T, src string) (string, string) {
+	dir, err := ioutil.TempDir("", "gocov-html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "p.go")
+	if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir, path
+}
+
+func TestFileLineCoverage(t *testing.T) {
+	src := `package p
+
+func f(a int) int {
+	if a > 0 { return 1 }
+	a++
+	return a
+}
+
+func newFixture() {
+	setup()
+}
+`
+	dir, path := writeSource(t, src)
+	defer os.RemoveAll(dir)
+	at := func(s string) int { return strings.Index(src, s) }
+	for _, helpers := range []bool{false, true} {
+		r := &report{ReportOptions: ReportOptions{PathBase: dir}}
+		if helpers {
+			r.helpers = regexp.MustCompile(`\.newFixture$`)
+		}
+		r.packages = []*gocov.Package{{Name: "p", Functions: []*gocov.Function{
+			{Name: "f", File: path, Start: at("func f"), End: at("}\n\n"), Statements: []*gocov.Statement{
+				{Start: at("if"), Reached: 2},
+				{Start: at("return 1"), Reached: 0},
+				{Start: at("a++"), Reached: 0},
+				{Start: at("return a"), Reached: 0},
+			}},
+			{Name: "newFixture", File: path, Start: at("func newFixture"), End: len(src) - 1, Statements: []*gocov.Statement{
+				{Start: at("setup"), Reached: 1},
+			}},
+		}}}
+		got, err := fileLineCoverage(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]*lineCoverage{
+			"p.go": {Covered: []int{4, 10}, Uncovered: []int{5, 6}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("fileLineCoverage() with helpers %v = %+v, want %+v", helpers, got["p.go"], want["p.go"])
+		}
+	}
+}
+
+func TestFileLineCoveragePackageFilter(t *testing.T) {
+	src := "package p\n\nfunc f() {\n\treturn\n}\n"
+	pkg := func(name string, reached int64) (*gocov.Package, string) {
+		dir, path := writeSource(t, src)
+		return &gocov.Package{Name: name, Functions: []*gocov.Function{{
+			Name: "f", File: path, Start: strings.Index(src, "func"), End: len(src) - 1,
+			Statements: []*gocov.Statement{{Start: strings.Index(src, "return"), Reached: reached}},
+		}}}, dir
+	}
+	covered, dir := pkg("covered", 1)
+	defer os.RemoveAll(dir)
+	uncovered, dir := pkg("uncovered", 0)
+	defer os.RemoveAll(dir)
+	max := 50.0
+	// Functions are all kept, whatever CoverageMax.
+	r := &report{ReportOptions: ReportOptions{CoverageMax: 0, MinHits: 1, PackageCoverageMax: &max}}
+	r.packages = []*gocov.Package{covered, uncovered}
+	got, err := fileLineCoverage(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]*lineCoverage{
+		uncovered.Functions[0].File: {Covered: []int{}, Uncovered: []int{4}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fileLineCoverage() = %v, want the files of the listed packages only", got)
+	}
+}
+
+func TestPrintDigest(t *testing.T) {
+	packages := []*gocov.Package{newPackage("a", 1, 1), newPackage("b", 0, 0), newPackage("c", 0, 0, 1, 1)}
+	tests := []struct {
+		name       string
+		count      int
+		descending bool
+		want       string
+	}{
+		{"all", 0, false, "  0.0%  b\n 50.0%  c\n100.0%  a\n"},
+		{"top", 2, false, "  0.0%  b\n 50.0%  c\n"},
+		{"descending", 1, true, "100.0%  a\n"},
+		{"count too big", 5, true, "100.0%  a\n 50.0%  c\n  0.0%  b\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &report{ReportOptions: ReportOptions{DigestCount: tt.count, DigestDescending: tt.descending}}
+			r.packages = packages
+			var buf bytes.Buffer
+			if err := printDigest(&buf, r); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("printDigest() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintSonar(t *testing.T) {
+	src := `package p
+
+func f(a int) int {
+	if a > 0 { return 1 }
+	a++
+	return a
+}
+`
+	dir, path := writeSource(t, src)
+	defer os.RemoveAll(dir)
+	at := func(s string) int { return strings.Index(src, s) }
+	r := &report{ReportOptions: ReportOptions{PathBase: dir}}
+	r.packages = []*gocov.Package{{Name: "p", Functions: []*gocov.Function{
+		{Name: "f", File: path, Start: at("func"), End: len(src) - 1, Statements: []*gocov.Statement{
+			{Start: at("if"), Reached: 2},
+			{Start: at("return 1"), Reached: 0},
+			{Start: at("a++"), Reached: 0},
+			{Start: at("return a"), Reached: 0},
+		}},
+	}}}
+	var buf bytes.Buffer
+	if err := printSonar(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	want := `<coverage version="1">
+  <file path="p.go">
+    <lineToCover lineNumber="4" covered="true"></lineToCover>
+    <lineToCover lineNumber="5" covered="false"></lineToCover>
+    <lineToCover lineNumber="6" covered="false"></lineToCover>
+  </file>
+</coverage>
+`
+	if got := buf.String(); got != want {
+		t.Errorf("printSonar() = %s, want %s", got, want)
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	tests := []struct {
+		n    int
+		sep  string
+		want string
+	}{
+		{124831, "", "124831"},
+		{0, ",", "0"},
+		{999, ",", "999"},
+		{1000, ",", "1,000"},
+		{124831, ",", "124,831"},
+		{1234567, " ", "1 234 567"},
+		{-1234567, ".", "-1.234.567"},
+	}
+	for _, tt := range tests {
+		if got := formatCount(tt.n, tt.sep); got != tt.want {
+			t.Errorf("formatCount(%d, %q) = %q, want %q", tt.n, tt.sep, got, tt.want)
+		}
+	}
+}
+
+func TestSummaryHeader(t *testing.T) {
+	max := 50.0
+	tests := []struct {
+		name string
+		opts ReportOptions
+		want string
+	}{
+		{"all packages", ReportOptions{}, "Total coverage: 42.9% (3/7 statements)"},
+		{"filtered packages", ReportOptions{PackageCoverageMax: &max}, "Total coverage: 20.0% (1/5 statements)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.CoverageMax, tt.opts.MinHits = 100, 1
+			r := &report{ReportOptions: tt.opts}
+			r.packages = []*gocov.Package{newPackage("a", 1, 1), newPackage("b", 0, 0), newPackage("c", 1, 0, 0)}
+			lines := summaryHeader(r)
+			if got := lines[len(lines)-1]; got != tt.want {
+				t.Errorf("summaryHeader() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteHeader(t *testing.T) {
+	src := "package p\n\nfunc f() {\n\treturn\n}\n"
+	dir, path := writeSource(t, src)
+	defer os.RemoveAll(dir)
+	headerFile := filepath.Join(dir, "header.txt")
+	tests := []struct {
+		format string
+		// First line of the report, empty if the header goes to the header file.
+		want string
+	}{
+		{FormatNDJSON, ""},
+		{FormatEditorMap, ""},
+		{FormatDigest, "# Generated on "},
+		{FormatSonar, "<!-- Generated on "},
+		{FormatPrometheus, "# Generated on "},
+		{FormatText, "# Generated on "},
+		{FormatMarkdown, "<!-- Generated on "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			os.Remove(headerFile)
+			r := newReport()
+			r.CoverageMax, r.MinHits = 100, 1
+			r.Format, r.Header, r.HeaderFile, r.PathBase = tt.format, true, headerFile, dir
+			r.packages = []*gocov.Package{{Name: "p", Functions: []*gocov.Function{{
+				Name: "f", File: path, Start: strings.Index(src, "func"), End: len(src) - 1,
+				Statements: []*gocov.Statement{{Start: strings.Index(src, "return"), Reached: 1}},
+			}}}}
+			var buf bytes.Buffer
+			if err := writeReport(&buf, r); err != nil {
+				t.Fatal(err)
+			}
+			out := buf.String()
+			if tt.want == "" {
+				if strings.Contains(out, "Generated on") {
+					t.Errorf("header written to the %s report:\n%s", tt.format, out)
+				}
+				if _, err := os.Stat(headerFile); err != nil {
+					t.Errorf("missing header file: %v", err)
+				}
+				return
+			}
+			lines := strings.Split(out, "\n")
+			if !strings.HasPrefix(lines[0], tt.want) {
+				t.Errorf("first line = %q, want prefix %q", lines[0], tt.want)
+			}
+			if strings.HasPrefix(tt.want, "<!--") {
+				for _, line := range lines[:2] {
+					if !strings.HasSuffix(line, " -->") {
+						t.Errorf("unclosed comment %q", line)
+					}
+				}
+			}
+			if tt.format == FormatSonar {
+				var cov sonarCoverage
+				if err := xml.Unmarshal(buf.Bytes(), &cov); err != nil {
+					t.Errorf("invalid XML: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestPrintPrometheus(t *testing.T) {
+	r := &report{ReportOptions: ReportOptions{CoverageMax: 100, MinHits: 1}}
+	r.packages = []*gocov.Package{
+		newPackage("example.com/a", 1, 1, 1),
+		newPackage(`b"\`, 1, 0, 0),
+	}
+	var buf bytes.Buffer
+	if err := printPrometheus(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP gocov_coverage_ratio Ratio of statements covered by tests, per package.
+# TYPE gocov_coverage_ratio gauge
+gocov_coverage_ratio{package="example.com/a"} 1
+gocov_coverage_ratio{package="b\"\\"} 0.3333
+# HELP gocov_total_coverage_ratio Ratio of statements covered by tests, all packages included.
+# TYPE gocov_total_coverage_ratio gauge
+gocov_total_coverage_ratio 0.6667
+`
+	if got := buf.String(); got != want {
+		t.Errorf("printPrometheus() = %s, want %s", got, want)
+	}
+}
+
+func TestParseSymbols(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    symbolSet
+		wantErr bool
+	}{
+		{"", symbolSet{"+", "-"}, false},
+		{"ascii", symbolSet{"+", "-"}, false},
+		{"unicode", symbolSet{"✓", "✗"}, false},
+		{"OK,KO", symbolSet{"OK", "KO"}, false},
+		{"OK", symbolSet{}, true},
+		{"OK,", symbolSet{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSymbols(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSymbols() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseSymbols() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintText(t *testing.T) {
+	r := &report{ReportOptions: ReportOptions{CoverageMax: 100, MinHits: 1, Symbols: "OK,KO"}}
+	r.packages = []*gocov.Package{{Name: "p", Functions: []*gocov.Function{
+		{Name: "f", File: "p/p.go", Statements: []*gocov.Statement{{Reached: 1}}},
+		{Name: "g", File: "p/p.go", Statements: []*gocov.Statement{{Reached: 1}, {Reached: 0}}},
+	}}}
+	var buf bytes.Buffer
+	if err := printText(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	want := `p            66.7%   2/3
+  OK f(...)  100.0%  1/1  p/p.go
+  KO g(...)  50.0%   1/2  p/p.go
+Total        66.7%   2/3
+`
+	if got := buf.String(); got != want {
+		t.Errorf("printText() = %q, want %q", got, want)
+	}
+}
+
+func TestPrintMarkdown(t *testing.T) {
+	r := &report{ReportOptions: ReportOptions{CoverageMax: 100, MinHits: 1}}
+	r.packages = []*gocov.Package{{Name: "p<q>", Functions: []*gocov.Function{
+		{Name: "f", File: "p/a|b.go", Statements: []*gocov.Statement{{Reached: 1}}},
+		{Name: "g", File: "p/p.go", Statements: []*gocov.Statement{{Reached: 1}, {Reached: 0}}},
+	}}}
+	table := `| Function | File | Coverage | Statements |
+|:---|:---|---:|---:|
+| ` + "`f(...)`" + ` | p/a\|b.go | 100.0% | 1/1 |
+| ` + "`g(...)`" + ` | p/p.go | 50.0% | 1/2 |
+`
+	tests := []struct {
+		name        string
+		collapsible bool
+		pkg         string
+		end         string
+	}{
+		{"flat", false, "### p<q> \u2014 66.7%\n\n", ""},
+		{"collapsible", true, "<details><summary>p&lt;q&gt; \u2014 66.7%</summary>\n\n", "\n</details>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r.MarkdownCollapsible = tt.collapsible
+			var buf bytes.Buffer
+			if err := printMarkdown(&buf, r); err != nil {
+				t.Fatal(err)
+			}
+			want := "## Coverage Report\n\nTotal coverage: **66.7%** (2/3 statements) of 1 package.\n\n" + tt.pkg + table + tt.end
+			if got := buf.String(); got != want {
+				t.Errorf("printMarkdown() = %q, want %q", got, want)
+			}
+		})
+	}
+}
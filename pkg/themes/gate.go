@@ -0,0 +1,159 @@
+package themes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/rotisserie/eris"
+)
+
+// ErrGateFailed is returned when at least one rule of the coverage gate fails.
+var ErrGateFailed = errors.New("coverage gate failed")
+
+// gateRule is the outcome of a single coverage threshold check.
+type gateRule struct {
+	// Name identifies the kind of rule.
+	Name string `json:"name"`
+	// Package is the package the rule applies to, if any.
+	Package string `json:"package,omitempty"`
+	// Function is the function the rule applies to, if any.
+	Function string `json:"function,omitempty"`
+	// Threshold is the minimum coverage percentage required. For the
+	// statements rule, it is the minimum change of the number of statements
+	// since the baseline, as a percentage.
+	Threshold float64 `json:"threshold"`
+	// Coverage is the actual coverage percentage. For the statements rule, it
+	// is the actual change of the number of statements, as a percentage.
+	Coverage float64 `json:"coverage"`
+	// BaseStatements and HeadStatements are the total number of statements of
+	// the baseline and of the report, for the statements rule.
+	BaseStatements int `json:"base_statements,omitempty"`
+	HeadStatements int `json:"head_statements,omitempty"`
+	// Passed is true if the coverage meets the threshold.
+	Passed bool `json:"passed"`
+}
+
+func (g gateRule) String() string {
+	if g.Name == "statements" {
+		return fmt.Sprintf("statements: %d -> %d (%+d, %+.1f%%), more than the %.1f%% tolerated were removed",
+			g.BaseStatements, g.HeadStatements, g.HeadStatements-g.BaseStatements, g.Coverage, -g.Threshold)
+	}
+	name := g.Name
+	if g.Package != "" {
+		name = fmt.Sprintf("%s %s", g.Name, g.Package)
+	}
+	if g.Function != "" {
+		name = fmt.Sprintf("%s.%s", name, g.Function)
+	}
+	return fmt.Sprintf("%s: %.1f%% < %.1f%%", name, g.Coverage, g.Threshold)
+}
+
+// gateResult is the verdict of the coverage gate.
+type gateResult struct {
+	// Passed is true if all the rules passed.
+	Passed bool `json:"passed"`
+	// Rules holds the outcome of every rule.
+	Rules []gateRule `json:"rules"`
+}
+
+// Failed returns the rules that did not pass.
+func (g *gateResult) Failed() []gateRule {
+	failed := make([]gateRule, 0)
+	for _, rule := range g.Rules {
+		if !rule.Passed {
+			failed = append(failed, rule)
+		}
+	}
+	return failed
+}
+
+// evalGate checks the coverage thresholds of the report options against the
+// report's packages. Returns nil if no threshold is set.
+func evalGate(r *report, packages reportPackageList) *gateResult {
+	gateFunctions := r.GateNewFunctions > 0 && r.baseline != nil
+	gateStatements := r.GateStatements && r.baseline != nil
+	if r.GateTotal <= 0 && r.GatePackage <= 0 && !gateFunctions && !gateStatements {
+		return nil
+	}
+	res := &gateResult{Passed: true, Rules: make([]gateRule, 0)}
+	add := func(g gateRule) {
+		g.Passed = g.Coverage >= g.Threshold
+		res.Passed = res.Passed && g.Passed
+		res.Rules = append(res.Rules, g)
+	}
+	if r.GateTotal > 0 {
+		total := reportPackage{}
+		for _, rp := range packages {
+			total.ReachedStatements += rp.ReachedStatements
+			total.TotalStatements += rp.TotalStatements
+		}
+		add(gateRule{Name: "total", Threshold: r.GateTotal, Coverage: total.PercentageReached()})
+	}
+	if r.GatePackage > 0 {
+		for _, rp := range packages {
+			add(gateRule{Name: "package", Package: rp.Pkg.Name, Threshold: r.GatePackage, Coverage: rp.PercentageReached()})
+		}
+	}
+	if gateFunctions || gateStatements {
+		u := r.unfiltered()
+		base := buildReportPackages(&report{ReportOptions: u.ReportOptions, packages: r.baseline})
+		head := buildReportPackages(u)
+		if gateFunctions {
+			for _, d := range diffFunctions(base, head) {
+				if d.New {
+					add(gateRule{Name: "new function", Package: d.Package, Function: d.Name, Threshold: r.GateNewFunctions, Coverage: d.Head})
+				} else {
+					add(gateRule{Name: "function", Package: d.Package, Function: d.Name, Threshold: d.Base, Coverage: d.Head})
+				}
+			}
+		}
+		if gateStatements {
+			if rule, ok := statementsRule(overview(base), overview(head), r.GateStatementsTolerance); ok {
+				add(rule)
+			}
+		}
+	}
+	return res
+}
+
+// statementsRule checks that no more than tolerance percent of the statements
+// of the baseline were removed, since deleted code or tests can hide coverage
+// regressions. There is no rule if the baseline has no statement.
+func statementsRule(base, head reportPackage, tolerance float64) (gateRule, bool) {
+	if base.TotalStatements == 0 {
+		return gateRule{}, false
+	}
+	change := float64(head.TotalStatements-base.TotalStatements) / float64(base.TotalStatements) * 100
+	return gateRule{
+		Name:           "statements",
+		Threshold:      -tolerance,
+		Coverage:       change,
+		BaseStatements: base.TotalStatements,
+		HeadStatements: head.TotalStatements,
+	}, true
+}
+
+// Err aggregates all failed rules into a single error wrapping ErrGateFailed.
+// Returns nil if the gate passed.
+func (g *gateResult) Err() error {
+	if g == nil || g.Passed {
+		return nil
+	}
+	msgs := make([]string, 0)
+	for _, rule := range g.Failed() {
+		msgs = append(msgs, rule.String())
+	}
+	return eris.Wrap(ErrGateFailed, strings.Join(msgs, "; "))
+}
+
+// writeFile saves the gate result as JSON.
+func (g *gateResult) writeFile(path string) error {
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return eris.Wrap(err, "marshal gate result")
+	}
+	return eris.Wrap(ioutil.WriteFile(path, data, 0644), "write gate result")
+}
@@ -0,0 +1,116 @@
+package themes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/axw/gocov"
+	"github.com/rotisserie/eris"
+)
+
+func TestEvalGate(t *testing.T) {
+	packages := reportPackageList{
+		{Pkg: &gocov.Package{Name: "a"}, ReachedStatements: 9, TotalStatements: 10},
+		{Pkg: &gocov.Package{Name: "b"}, ReachedStatements: 5, TotalStatements: 10},
+	}
+	tests := []struct {
+		name       string
+		opts       ReportOptions
+		wantNil    bool
+		wantRules  int
+		wantPassed bool
+	}{
+		{"no rule", ReportOptions{}, true, 0, true},
+		{"total passes at boundary", ReportOptions{GateTotal: 70}, false, 1, true},
+		{"total fails", ReportOptions{GateTotal: 70.1}, false, 1, false},
+		{"package fails", ReportOptions{GatePackage: 60}, false, 2, false},
+		{"all pass", ReportOptions{GateTotal: 50, GatePackage: 50}, false, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evalGate(&report{ReportOptions: tt.opts}, packages)
+			if tt.wantNil {
+				if got != nil {
+					t.Fatalf("evalGate() = %v, want nil", got)
+				}
+				return
+			}
+			if len(got.Rules) != tt.wantRules {
+				t.Errorf("got %d rules, want %d", len(got.Rules), tt.wantRules)
+			}
+			if got.Passed != tt.wantPassed {
+				t.Errorf("Passed = %v, want %v", got.Passed, tt.wantPassed)
+			}
+			if err := got.Err(); (err == nil) != tt.wantPassed || (err != nil && !eris.Is(err, ErrGateFailed)) {
+				t.Errorf("Err() = %v", err)
+			}
+		})
+	}
+}
+
+func TestStatementsRule(t *testing.T) {
+	stmts := func(n int) []*gocov.Statement {
+		rv := make([]*gocov.Statement, n)
+		for i := range rv {
+			rv[i] = &gocov.Statement{Reached: 1}
+		}
+		return rv
+	}
+	base := []*gocov.Package{{Name: "p", Functions: []*gocov.Function{
+		{Name: "f", Statements: stmts(60)},
+		{Name: "g", Statements: stmts(40)},
+	}}}
+	tests := []struct {
+		name       string
+		head       int
+		tolerance  float64
+		wantPassed bool
+	}{
+		{"more statements", 110, 0, true},
+		{"same statements", 100, 0, true},
+		{"any decrease fails", 99, 0, false},
+		{"within tolerance", 95, 5, true},
+		{"beyond tolerance", 94, 5, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &report{
+				ReportOptions: ReportOptions{CoverageMax: 100, MinHits: 1, GateStatements: true, GateStatementsTolerance: tt.tolerance},
+				baseline:      base,
+			}
+			r.packages = []*gocov.Package{{Name: "p", Functions: []*gocov.Function{{Name: "f", Statements: stmts(tt.head)}}}}
+			got := evalGate(r, buildReportPackages(r))
+			if len(got.Rules) != 1 {
+				t.Fatalf("got %d rules, want 1", len(got.Rules))
+			}
+			rule := got.Rules[0]
+			if rule.Passed != tt.wantPassed {
+				t.Errorf("Passed = %v, want %v: %s", rule.Passed, tt.wantPassed, rule)
+			}
+			if rule.BaseStatements != 100 || rule.HeadStatements != tt.head {
+				t.Errorf("got %d -> %d statements, want 100 -> %d", rule.BaseStatements, rule.HeadStatements, tt.head)
+			}
+		})
+	}
+
+	rule, _ := statementsRule(reportPackage{TotalStatements: 1000}, reportPackage{TotalStatements: 875}, 5)
+	if want := "statements: 1000 -> 875 (-125, -12.5%), more than the 5.0% tolerated were removed"; rule.String() != want {
+		t.Errorf("String() = %q, want %q", rule.String(), want)
+	}
+	if _, ok := statementsRule(reportPackage{}, reportPackage{TotalStatements: 10}, 0); ok {
+		t.Error("got a rule for a baseline without statements")
+	}
+}
+
+func TestBaselineRequired(t *testing.T) {
+	for name, opts := range map[string]ReportOptions{
+		"functions":  {GateNewFunctions: 80},
+		"statements": {GateStatements: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := loadReport(strings.NewReader(`{"Packages":[]}`), opts); err == nil {
+				t.Error("got no error without a baseline")
+			}
+		})
+	}
+}
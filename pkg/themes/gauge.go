@@ -0,0 +1,36 @@
+package themes
+
+import (
+	"fmt"
+	"math"
+)
+
+// gaugeRadius is the radius of the circle of the coverage gauge.
+const gaugeRadius = 40
+
+// gaugeColor returns the color of the coverage gauge: red below 50%, orange
+// below 80%, green otherwise.
+func gaugeColor(percent float64) string {
+	switch {
+	case percent < 50:
+		return "#d9534f"
+	case percent < 80:
+		return "#f0ad4e"
+	}
+	return "#5cb85c"
+}
+
+// gaugeSVG returns a self-contained circular gauge of the coverage percentage,
+// as an inline SVG image.
+func gaugeSVG(percent float64) string {
+	percent = math.Max(0, math.Min(100, percent))
+	circumference := 2 * math.Pi * gaugeRadius
+	label := fmt.Sprintf("%.1f%%", percent)
+	return fmt.Sprintf(`<svg class="gauge" xmlns="http://www.w3.org/2000/svg" width="120" height="120" viewBox="0 0 100 100" role="img" aria-label="Total coverage: %[1]s">`+
+		`<title>Total coverage: %[1]s</title>`+
+		`<circle cx="50" cy="50" r="%[2]d" fill="none" stroke="#e6e6e6" stroke-width="10"/>`+
+		`<circle cx="50" cy="50" r="%[2]d" fill="none" stroke="%[3]s" stroke-width="10" stroke-dasharray="%.2[4]f %.2[5]f" transform="rotate(-90 50 50)"/>`+
+		`<text x="50" y="50" text-anchor="middle" dominant-baseline="central" font-family="sans-serif" font-size="16" fill="#333">%[1]s</text>`+
+		`</svg>`,
+		label, gaugeRadius, gaugeColor(percent), circumference*percent/100, circumference)
+}
@@ -0,0 +1,37 @@
+package themes
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestGaugeSVG(t *testing.T) {
+	tests := []struct {
+		name    string
+		percent float64
+		label   string
+		color   string
+		filled  string
+	}{
+		{"empty", 0, "0.0%", "#d9534f", `stroke-dasharray="0.00 251.33"`},
+		{"low", 49.9, "49.9%", "#d9534f", `stroke-dasharray="125.41 251.33"`},
+		{"medium", 50, "50.0%", "#f0ad4e", `stroke-dasharray="125.66 251.33"`},
+		{"high", 80, "80.0%", "#5cb85c", `stroke-dasharray="201.06 251.33"`},
+		{"full", 100, "100.0%", "#5cb85c", `stroke-dasharray="251.33 251.33"`},
+		{"out of range", 120, "100.0%", "#5cb85c", `stroke-dasharray="251.33 251.33"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := gaugeSVG(tt.percent)
+			if err := xml.Unmarshal([]byte(got), new(struct{})); err != nil {
+				t.Fatalf("invalid SVG: %v", err)
+			}
+			for _, want := range []string{`aria-label="Total coverage: ` + tt.label + `"`, `stroke="` + tt.color + `"`, tt.filled} {
+				if !strings.Contains(got, want) {
+					t.Errorf("gaugeSVG() = %s, missing %s", got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package themes
+
+import "github.com/axw/gocov"
+
+// newStatements returns statements reached the given numbers of times.
+func newStatements(reached ...int64) []*gocov.Statement {
+	var rv []*gocov.Statement
+	for _, n := range reached {
+		rv = append(rv, &gocov.Statement{Reached: n})
+	}
+	return rv
+}
+
+// newFunction returns a function whose statements are reached the given
+// numbers of times.
+func newFunction(name string, reached ...int64) *gocov.Function {
+	return &gocov.Function{Name: name, Statements: newStatements(reached...)}
+}
+
+// newPackage returns a package with a single function f, whose statements are
+// reached the given numbers of times.
+func newPackage(name string, reached ...int64) *gocov.Package {
+	return &gocov.Package{Name: name, Functions: []*gocov.Function{newFunction("f", reached...)}}
+}
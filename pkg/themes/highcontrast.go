@@ -0,0 +1,23 @@
+package themes
+
+//go:generate ../../generator
+
+// highContrastTheme renders the template of the golang theme with a stylesheet
+// meeting the WCAG AAA contrast ratios, for users with low vision.
+type highContrastTheme struct{}
+
+func (t highContrastTheme) Assets() StaticAssets {
+	return StaticAssets{
+		Stylesheets: []string{"style.css"},
+		// Same structure as the golang theme.
+		Index: "../golang/index.html",
+	}
+}
+
+func (t highContrastTheme) Name() string {
+	return "high-contrast"
+}
+
+func (t highContrastTheme) Description() string {
+	return "high contrast theme (WCAG AAA)"
+}
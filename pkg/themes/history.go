@@ -0,0 +1,137 @@
+package themes
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// defaultHistoryLength is the number of recorded summaries read from the
+// history directory if no length is set.
+const defaultHistoryLength = 10
+
+// historyExt is the extension of the summaries of the history directory, as
+// written by the NDJSON output format.
+const historyExt = ".ndjson"
+
+// coverageHistory holds the coverage percentages of the recorded runs, oldest
+// first, per package name. The percentages of the overview of all packages
+// are indexed by an empty name. Runs where a package is missing are skipped
+// for it.
+type coverageHistory map[string][]float64
+
+// loadHistory reads the last n summaries of dir, in file name order. Summaries
+// are files with the ndjson extension written by the NDJSON output format, so
+// naming them after the date or the build number of their run keeps them in
+// order. Unreadable files and lines are ignored: the history only adds
+// context to a report. Returns nil if dir can't be read.
+func loadHistory(dir string, n int) coverageHistory {
+	if n <= 0 {
+		n = defaultHistoryLength
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, fi := range infos {
+		if fi.Mode().IsRegular() && strings.HasSuffix(fi.Name(), historyExt) {
+			files = append(files, fi.Name())
+		}
+	}
+	sort.Strings(files)
+	if len(files) > n {
+		files = files[len(files)-n:]
+	}
+	h := make(coverageHistory)
+	for _, name := range files {
+		readSummary(filepath.Join(dir, name), h)
+	}
+	return h
+}
+
+// readSummary appends the percentages of the NDJSON summary at path to h.
+func readSummary(path string, h coverageHistory) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		var ps packageSummary
+		if err := json.Unmarshal(s.Bytes(), &ps); err != nil {
+			continue
+		}
+		name := ps.Name
+		if ps.Overview {
+			name = ""
+		}
+		h[name] = append(h[name], ps.Percentage)
+	}
+}
+
+// trend returns the sparkline of the recorded coverage of a package followed
+// by its current one. Empty without any recorded coverage.
+func (h coverageHistory) trend(name string, current float64) string {
+	recorded := h[name]
+	if len(recorded) == 0 {
+		return ""
+	}
+	return sparklineSVG(append(recorded[:len(recorded):len(recorded)], current))
+}
+
+// Size of a sparkline, in pixels.
+const (
+	sparklineWidth  = 60
+	sparklineHeight = 16
+)
+
+// sparklineSVG returns an inline SVG image plotting the coverage percentages,
+// from left to right, scaled to their own range so that small changes remain
+// visible. The last value is marked with a dot. Uses the current text color.
+func sparklineSVG(values []float64) string {
+	min, max := values[0], values[0]
+	for _, v := range values {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	// Points are kept 2 pixels away from the edges, for the dot to fit.
+	const pad = 2
+	x := func(i int) float64 {
+		if len(values) == 1 {
+			return sparklineWidth - pad
+		}
+		return pad + float64(i)*(sparklineWidth-2*pad)/float64(len(values)-1)
+	}
+	y := func(v float64) float64 {
+		if max == min {
+			return sparklineHeight / 2
+		}
+		return pad + (max-v)*(sparklineHeight-2*pad)/(max-min)
+	}
+	points := make([]string, len(values))
+	labels := make([]string, len(values))
+	for i, v := range values {
+		points[i] = fmt.Sprintf("%.1f,%.1f", x(i), y(v))
+		labels[i] = fmt.Sprintf("%.1f%%", v)
+	}
+	last := len(values) - 1
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" class="sparkline" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-label="Coverage trend">`,
+		sparklineWidth, sparklineHeight, sparklineWidth, sparklineHeight)
+	fmt.Fprintf(&b, `<title>Coverage of the last %d runs: %s</title>`, len(values), strings.Join(labels, ", "))
+	fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="currentColor" stroke-width="1.5" stroke-linejoin="round"/>`, strings.Join(points, " "))
+	fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="2" fill="currentColor"/>`, x(last), y(values[last]))
+	b.WriteString(`</svg>`)
+	return b.String()
+}
@@ -0,0 +1,63 @@
+package themes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gocov-html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"1.ndjson": `{"name":"a","percentage":10}` + "\n" + `{"name":"Report Total","percentage":10,"overview":true}`,
+		"2.ndjson": `{"name":"a","percentage":20}` + "\n" + `{"name":"b","percentage":50}` + "\n" + `{"name":"Report Total","percentage":30,"overview":true}`,
+		"3.ndjson": "not json\n" + `{"name":"a","percentage":30}`,
+		"4.json":   `{"name":"a","percentage":40}`,
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := loadHistory(dir, 0)
+	want := coverageHistory{"a": {10, 20, 30}, "b": {50}, "": {10, 30}}
+	if !reflect.DeepEqual(h, want) {
+		t.Errorf("got history %v, want %v", h, want)
+	}
+	h = loadHistory(dir, 2)
+	want = coverageHistory{"a": {20, 30}, "b": {50}, "": {30}}
+	if !reflect.DeepEqual(h, want) {
+		t.Errorf("got last 2 summaries %v, want %v", h, want)
+	}
+	if h := loadHistory(filepath.Join(dir, "missing"), 0); h != nil {
+		t.Errorf("got history %v for a missing directory", h)
+	}
+}
+
+func TestTrend(t *testing.T) {
+	h := coverageHistory{"a": {10, 20}}
+	got := h.trend("a", 15)
+	for _, want := range []string{"<svg", "last 3 runs: 10.0%, 20.0%, 15.0%", `points="2.0,14.0 30.0,2.0 58.0,8.0"`, `cx="58.0" cy="8.0"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("trend %s: missing %q", got, want)
+		}
+	}
+	if got := h.trend("b", 15); got != "" {
+		t.Errorf("got trend %q for a package without history", got)
+	}
+	if got := coverageHistory(nil).trend("a", 15); got != "" {
+		t.Errorf("got trend %q without history", got)
+	}
+	// Constant coverage is drawn at mid height.
+	if got := sparklineSVG([]float64{50, 50}); !strings.Contains(got, `points="2.0,8.0 58.0,8.0"`) {
+		t.Errorf("got sparkline %s", got)
+	}
+}
@@ -0,0 +1,216 @@
+package themes
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/rotisserie/eris"
+)
+
+// IndexInput is one of the reports of a combined index, like the coverage
+// data of a service of a monorepo.
+type IndexInput struct {
+	// Name of the report, shown in the index. The file name of the report is
+	// derived from it.
+	Name string
+	// Data is the coverage data of the report.
+	Data io.Reader
+}
+
+// indexEntry is the summary of a report in the index page.
+type indexEntry struct {
+	Name string
+	// File is the file name of the report, relative to the index page.
+	File     string
+	Packages int
+	statementCount
+}
+
+// indexData is the data of the index page template.
+type indexData struct {
+	When       string
+	ProjectURL string
+	// BaseHref is the URL the reports are hosted at, prefixed to their links.
+	BaseHref string
+	Reports  []*indexEntry
+	Total    statementCount
+	// ThousandsSeparator is inserted between groups of thousands of counts.
+	ThousandsSeparator string
+}
+
+// Count formats a number of statements, with thousands separators if set.
+func (d *indexData) Count(n int) string {
+	return formatCount(n, d.ThousandsSeparator)
+}
+
+var reportFileUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// reportFileName returns the file name of the report of an index input.
+func reportFileName(name string) string {
+	return strings.Trim(reportFileUnsafe.ReplaceAllString(name, "-"), "-.") + ".html"
+}
+
+// WriteIndex writes the HTML report of every input to its own file in dir,
+// named after the input, then an index.html page linking to all of them, with
+// their total coverage and the grand total of all reports. The options apply
+// to every report; only the HTML format is supported, without compression nor
+// gate result file.
+//
+// All reports are written even if the coverage gate of some of them fails. An
+// error wrapping ErrGateFailed is returned in that case.
+func WriteIndex(dir string, inputs []IndexInput, opts ReportOptions) error {
+	if opts.Format != "" && opts.Format != FormatHTML {
+		return eris.Errorf("index: unsupported %s format", opts.Format)
+	}
+	if opts.Gzip {
+		return eris.New("index: gzip not supported")
+	}
+	if opts.GateResult != "" {
+		return eris.New("index: gate result file not supported")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return eris.Wrap(err, "index")
+	}
+	href, err := normalizeBaseHref(opts.BaseHref)
+	if err != nil {
+		return eris.Wrap(err, "index")
+	}
+	data := &indexData{
+		When:               time.Now().Format(time.RFC1123),
+		ProjectURL:         ProjectURL,
+		BaseHref:           href,
+		ThousandsSeparator: opts.ThousandsSeparator,
+	}
+	files := make(map[string]string)
+	var failed []string
+	for _, in := range inputs {
+		file := reportFileName(in.Name)
+		if file == ".html" || file == "index.html" {
+			return eris.Errorf("index: invalid report name %q", in.Name)
+		}
+		if other, ok := files[file]; ok {
+			return eris.Errorf("index: reports %q and %q have the same file name %s", other, in.Name, file)
+		}
+		files[file] = in.Name
+
+		t0 := time.Now()
+		r, err := loadReport(in.Data, opts)
+		if err != nil {
+			return eris.Wrapf(err, "index: %s", in.Name)
+		}
+		_, totals := r.listedPackages(buildReportPackages(r))
+		err = writeIndexReport(filepath.Join(dir, file), r, t0)
+		if eris.Is(err, ErrGateFailed) {
+			for _, rule := range r.gate.Failed() {
+				failed = append(failed, in.Name+": "+rule.String())
+			}
+		} else if err != nil {
+			return eris.Wrapf(err, "index: %s", in.Name)
+		}
+		ov := overview(totals)
+		entry := &indexEntry{Name: in.Name, File: file, Packages: len(totals)}
+		entry.TotalStatements, entry.ReachedStatements = ov.TotalStatements, ov.ReachedStatements
+		data.Reports = append(data.Reports, entry)
+		data.Total.TotalStatements += ov.TotalStatements
+		data.Total.ReachedStatements += ov.ReachedStatements
+	}
+
+	var buf bytes.Buffer
+	if err := indexTemplate.Execute(&buf, data); err != nil {
+		return eris.Wrap(err, "index: execute template")
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "index.html"), buf.Bytes(), 0644); err != nil {
+		return eris.Wrap(err, "index")
+	}
+	if len(failed) > 0 {
+		return eris.Wrap(ErrGateFailed, strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// writeIndexReport writes the report, loaded since t0, to path.
+func writeIndexReport(path string, r *report, t0 time.Time) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return eris.Wrap(err, "create report")
+	}
+	err = writeLoadedReport(f, r, t0)
+	if cerr := f.Close(); cerr != nil && (err == nil || eris.Is(err, ErrGateFailed)) {
+		err = eris.Wrap(cerr, "close report")
+	}
+	return err
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="utf-8">
+    <title>Coverage Reports</title>
+    <style type="text/css">
+    body {
+        font-family: "Helvetica Neue", Helvetica, Arial, sans-serif;
+        margin: 20px;
+    }
+    table {
+        border-collapse: collapse;
+    }
+    th, td {
+        padding: 5px 20px 5px 0;
+        text-align: left;
+    }
+    td.number, th.number {
+        text-align: right;
+    }
+    tfoot th {
+        border-top: 1px solid #ccc;
+    }
+    a {
+        color: #375eab;
+        text-decoration: none;
+    }
+    a:hover {
+        text-decoration: underline;
+    }
+    </style>
+</head>
+<body>
+    <h1>Coverage Reports</h1>
+    <p>Generated on {{.When}} with <a href="{{.ProjectURL}}">gocov-html</a></p>
+    <table>
+        <thead>
+            <tr>
+                <th>Report</th>
+                <th class="number">Coverage</th>
+                <th class="number">Statements</th>
+                <th class="number">Packages</th>
+            </tr>
+        </thead>
+        <tbody>
+        {{range .Reports}}
+            <tr>
+                <td><a href="{{html $.BaseHref}}{{html .File}}">{{html .Name}}</a></td>
+                <td class="number">{{printf "%.1f%%" .PercentageReached}}</td>
+                <td class="number">{{$.Count .ReachedStatements}}/{{$.Count .TotalStatements}}</td>
+                <td class="number">{{$.Count .Packages}}</td>
+            </tr>
+        {{end}}
+        </tbody>
+        <tfoot>
+            <tr>
+                <th>Total</th>
+                <th class="number">{{printf "%.1f%%" .Total.PercentageReached}}</th>
+                <th class="number">{{$.Count .Total.ReachedStatements}}/{{$.Count .Total.TotalStatements}}</th>
+                <th></th>
+            </tr>
+        </tfoot>
+    </table>
+</body>
+</html>
+`))
@@ -0,0 +1,102 @@
+package themes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rotisserie/eris"
+)
+
+func TestReportFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"api", "api.html"},
+		{"billing/worker", "billing-worker.html"},
+		{"../etc", "etc.html"},
+		{"Web UI", "Web-UI.html"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reportFileName(tt.name); got != tt.want {
+				t.Errorf("reportFileName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gocov-html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cov := func(reached ...string) *strings.Reader {
+		return strings.NewReader(`{"Packages":[{"Name":"p","Functions":[{"Name":"f","Statements":[` +
+			strings.Join(reached, ",") + `]}]}]}`)
+	}
+	hit, miss := `{"Reached":1}`, `{"Reached":0}`
+	inputs := []IndexInput{
+		{Name: "api", Data: cov(hit, hit, hit, miss)},
+		{Name: "billing/worker", Data: cov(hit, miss)},
+	}
+	opts := ReportOptions{CoverageMax: 100, MinHits: 1, HideSource: true}
+	if err := WriteIndex(dir, inputs, opts); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"api.html", "billing-worker.html"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("missing report: %v", err)
+		}
+	}
+	index, err := ioutil.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`<a href="api.html">api</a>`, "75.0%", `<a href="billing-worker.html">billing/worker</a>`, "50.0%", "4/6"} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("index misses %q", want)
+		}
+	}
+
+	opts.BaseHref = "/reports/run-42"
+	if err := WriteIndex(dir, []IndexInput{{Name: "api", Data: cov(hit)}}, opts); err != nil {
+		t.Fatal(err)
+	}
+	index, err = ioutil.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `<a href="/reports/run-42/api.html">`; !strings.Contains(string(index), want) {
+		t.Errorf("index misses %q", want)
+	}
+	opts.BaseHref = ""
+
+	inputs = []IndexInput{{Name: "a b", Data: cov(hit)}, {Name: "a/b", Data: cov(hit)}}
+	if err := WriteIndex(dir, inputs, opts); err == nil {
+		t.Error("got no error for reports with the same file name")
+	}
+	opts.GateTotal = 80
+	inputs = []IndexInput{{Name: "api", Data: cov(hit, miss)}, {Name: "web", Data: cov(miss)}}
+	err = WriteIndex(dir, inputs, opts)
+	if !eris.Is(err, ErrGateFailed) {
+		t.Fatalf("WriteIndex() error = %v, want a gate failure", err)
+	}
+	if msg := err.Error(); strings.Count(msg, ErrGateFailed.Error()) != 1 || !strings.Contains(msg, "api: ") || !strings.Contains(msg, "web: ") {
+		t.Errorf("WriteIndex() error = %q, want the failures of both reports", msg)
+	}
+	opts.GateResult = filepath.Join(dir, "gate.json")
+	if err := WriteIndex(dir, nil, opts); err == nil {
+		t.Error("got no error for a gate result file")
+	}
+	opts.GateTotal, opts.GateResult = 0, ""
+
+	opts.Format = FormatDigest
+	if err := WriteIndex(dir, nil, opts); err == nil {
+		t.Error("got no error for a non-HTML format")
+	}
+}
@@ -0,0 +1,61 @@
+package themes
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/rotisserie/eris"
+)
+
+// Output channels of the large functions warnings.
+const (
+	largeFunctionsReport = "report"
+	largeFunctionsStderr = "stderr"
+	largeFunctionsBoth   = "both"
+)
+
+func validLargeFunctionsOutput(output string) bool {
+	switch output {
+	case "", largeFunctionsReport, largeFunctionsStderr, largeFunctionsBoth:
+		return true
+	}
+	return false
+}
+
+// largeFunctions returns the functions of the packages, helpers included,
+// with more than threshold statements, whatever their coverage. Largest
+// functions come first.
+func largeFunctions(packages reportPackageList, threshold int) focusedFunctionList {
+	fns := make(focusedFunctionList, 0)
+	for _, rp := range packages {
+		for _, list := range []reportFunctionList{rp.Functions, rp.helperFunctions()} {
+			for _, f := range list {
+				if len(f.Statements) > threshold {
+					fns = append(fns, focusedFunction{reportFunction: f, Pkg: rp.Pkg})
+				}
+			}
+		}
+	}
+	sort.SliceStable(fns, func(i, j int) bool {
+		return len(fns[i].Statements) > len(fns[j].Statements)
+	})
+	return fns
+}
+
+// warnLargeFunctions writes a lint-style warning for every large function,
+// located at the line the function starts at if the source file is available.
+func warnLargeFunctions(w io.Writer, fns focusedFunctionList, threshold int) error {
+	for _, f := range fns {
+		pos := f.DisplayFile
+		if src, err := loadSource(f.File); err == nil {
+			pos = fmt.Sprintf("%s:%d", pos, src.Line(f.Start))
+		}
+		_, err := fmt.Fprintf(w, "%s: warning: %s.%s has %d statements, more than %d\n",
+			pos, f.Pkg.Name, f.Name, len(f.Statements), threshold)
+		if err != nil {
+			return eris.Wrap(err, "warn large functions")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package themes
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/axw/gocov"
+)
+
+func TestLargeFunctions(t *testing.T) {
+	stmts := func(n int) []*gocov.Statement {
+		rv := make([]*gocov.Statement, n)
+		for i := range rv {
+			rv[i] = &gocov.Statement{}
+		}
+		return rv
+	}
+	r := &report{ReportOptions: ReportOptions{CoverageMax: 100, MinHits: 1, Helpers: `\.help`}}
+	r.helpers = regexp.MustCompile(r.Helpers)
+	r.packages = []*gocov.Package{{Name: "p", Functions: []*gocov.Function{
+		{Name: "small", File: "p/p.go", Statements: stmts(3)},
+		{Name: "large", File: "p/p.go", Statements: stmts(5)},
+		{Name: "limit", File: "p/p.go", Statements: stmts(4)},
+		{Name: "helpLarge", File: "p/p.go", Statements: stmts(8)},
+	}}}
+	fns := largeFunctions(buildReportPackages(r), 4)
+	var names []string
+	for _, f := range fns {
+		names = append(names, f.Name)
+	}
+	if want := []string{"helpLarge", "large"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got %v, want %v", names, want)
+	}
+
+	var buf bytes.Buffer
+	if err := warnLargeFunctions(&buf, fns[1:], 4); err != nil {
+		t.Fatal(err)
+	}
+	if want := "p/p.go: warning: p.large has 5 statements, more than 4\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
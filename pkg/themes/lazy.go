@@ -0,0 +1,29 @@
+package themes
+
+import "encoding/json"
+
+// lazyLine is a line of the source of a function, as embedded in the page
+// for lazy rendering.
+type lazyLine struct {
+	Line   int    `json:"line"`
+	Code   string `json:"code"`
+	Missed bool   `json:"missed,omitempty"`
+}
+
+// LinesJSON returns the lines of the source of the function as a JSON array,
+// rendered by the page the first time the source is expanded. Code is HTML
+// escaped. The result is safe to embed in a script element: json escapes the
+// < and > characters.
+func (f reportFunction) LinesJSON() string {
+	lines := f.Lines()
+	rv := make([]lazyLine, len(lines))
+	for i, l := range lines {
+		rv[i] = lazyLine{Line: l.LineNumber, Code: l.Code, Missed: l.Missed}
+	}
+	data, err := json.Marshal(rv)
+	if err != nil {
+		// Strings and numbers can't fail.
+		panic(err)
+	}
+	return string(data)
+}
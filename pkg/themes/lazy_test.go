@@ -0,0 +1,118 @@
+package themes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/axw/gocov"
+)
+
+func TestLinesJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gocov-html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src := "func f() {\n\tif a < b {\n\t\treturn\n\t}\n}"
+	path := filepath.Join(dir, "f.go")
+	if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f := reportFunction{Function: &gocov.Function{
+		Name: "f", File: path, Start: 0, End: len(src),
+		Statements: []*gocov.Statement{{Start: strings.Index(src, "if"), Reached: 1}, {Start: strings.Index(src, "return")}},
+	}}
+	got := f.LinesJSON()
+	if strings.Contains(got, "<") {
+		t.Errorf("LinesJSON() = %s, not safe in a script element", got)
+	}
+	var lines []lazyLine
+	if err := json.Unmarshal([]byte(got), &lines); err != nil {
+		t.Fatal(err)
+	}
+	want := []lazyLine{
+		{1, "func f() {", false},
+		{2, "        if a &lt; b {", false},
+		{3, "                return", true},
+		{4, "        }", false},
+		{5, "}", false},
+	}
+	if fmt.Sprint(lines) != fmt.Sprint(want) {
+		t.Errorf("got lines %v, want %v", lines, want)
+	}
+}
+
+// TestLazySourceInitialLoad measures the number of elements of the page
+// before any function is expanded, for a package with long functions.
+func TestLazySourceInitialLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gocov-html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var src strings.Builder
+	pkg := &gocov.Package{Name: "p"}
+	for i := 0; i < 20; i++ {
+		fn := &gocov.Function{Name: fmt.Sprintf("f%d", i), File: filepath.Join(dir, "p.go"), Start: src.Len()}
+		fmt.Fprintf(&src, "func f%d() {\n", i)
+		for j := 0; j < 50; j++ {
+			fn.Statements = append(fn.Statements, &gocov.Statement{Start: src.Len(), Reached: int64(j % 2)})
+			fmt.Fprintf(&src, "\tx += %d\n", j)
+		}
+		src.WriteString("}\n")
+		fn.End = src.Len() - 1
+		pkg.Functions = append(pkg.Functions, fn)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "p.go"), []byte(src.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(struct{ Packages []*gocov.Package }{[]*gocov.Package{pkg}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, th := range List() {
+		t.Run(th.Name(), func(t *testing.T) {
+			defer func(old Beautifier) { curTheme = old }(curTheme)
+			curTheme = th
+			elements := func(opts ReportOptions) (int, int) {
+				var buf bytes.Buffer
+				opts.CoverageMax, opts.MinHits = 100, 1
+				if err := WriteReportCoverage(&buf, bytes.NewReader(data), opts); err != nil {
+					t.Fatal(err)
+				}
+				page := buf.String()
+				// Elements of noscript and script elements aren't parsed when
+				// javascript is enabled.
+				for _, tag := range []string{"noscript", "script"} {
+					for {
+						i := strings.Index(page, "<"+tag)
+						j := strings.Index(page, "</"+tag+">")
+						if i < 0 || j < i {
+							break
+						}
+						page = page[:i] + page[j+len(tag)+3:]
+					}
+				}
+				return strings.Count(page, "<") - strings.Count(page, "</"), buf.Len()
+			}
+			bare, _ := elements(ReportOptions{HideSource: true})
+			full, fullSize := elements(ReportOptions{})
+			lazy, lazySize := elements(ReportOptions{LazySource: true})
+			t.Logf("initial elements of the source: %d, lazy: %d; page size: %d bytes, lazy: %d bytes", full-bare, lazy-bare, fullSize, lazySize)
+			if (lazy-bare)*10 > full-bare {
+				t.Errorf("got %d initial elements for the lazy source, want at most a tenth of %d", lazy-bare, full-bare)
+			}
+			noscript, _ := elements(ReportOptions{LazySource: true, LazySourceNoScript: true})
+			if noscript != lazy {
+				t.Errorf("got %d initial elements with noscript source, want %d", noscript, lazy)
+			}
+		})
+	}
+}
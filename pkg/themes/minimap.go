@@ -0,0 +1,90 @@
+package themes
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// minimapMinLines is the number of lines from which the source of a function
+// gets a minimap. Shorter functions fit on screen anyway.
+const minimapMinLines = 30
+
+// Coverage states of the lines of a minimap.
+const (
+	lineNeutral = iota
+	lineCovered
+	lineMissed
+)
+
+// minimapColors holds the default colors of the regions of a minimap, per line
+// state. Themes can override them with the "hit" and "miss" CSS classes.
+var minimapColors = map[int]string{
+	lineNeutral: "#e6e6e6",
+	lineCovered: "#5cb85c",
+	lineMissed:  "#d9534f",
+}
+
+// minimapRegion is a run of consecutive lines sharing the same coverage state.
+type minimapRegion struct {
+	// First and Last are indexes in the lines of the function.
+	First, Last int
+	State       int
+}
+
+func lineState(l functionLine) int {
+	switch {
+	case l.Missed:
+		return lineMissed
+	case l.Covered:
+		return lineCovered
+	}
+	return lineNeutral
+}
+
+// minimapRegions groups consecutive lines by coverage state. Lines without
+// statement belong to the region before them, so that a covered or missed
+// block isn't split by blank lines or comments.
+func minimapRegions(lines []functionLine) []minimapRegion {
+	var rv []minimapRegion
+	for i, l := range lines {
+		state := lineState(l)
+		if n := len(rv); n > 0 {
+			last := &rv[n-1]
+			if state == last.State || state == lineNeutral {
+				last.Last = i
+				continue
+			}
+		}
+		rv = append(rv, minimapRegion{First: i, Last: i, State: state})
+	}
+	return rv
+}
+
+// Minimap returns a vertical strip summarizing the covered and missed regions
+// of the source of the function, as an inline SVG image. Clicking a region
+// scrolls to its first line, whose row id is "fn_<Anchor>_L<line number>". Returns
+// an empty string for functions shorter than minimapMinLines lines.
+func (f reportFunction) Minimap() string {
+	lines := f.Lines()
+	if len(lines) < minimapMinLines {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="12" height="100%%" viewBox="0 0 1 %d" preserveAspectRatio="none" role="img" aria-label="Coverage minimap">`, len(lines))
+	fmt.Fprintf(&b, `<rect width="1" height="%d" fill="%s"/>`, len(lines), minimapColors[lineNeutral])
+	for _, r := range minimapRegions(lines) {
+		class, status := "hit", "covered"
+		switch r.State {
+		case lineNeutral:
+			continue
+		case lineMissed:
+			class, status = "miss", "not covered"
+		}
+		first, last := lines[r.First].LineNumber, lines[r.Last].LineNumber
+		fmt.Fprintf(&b, `<a href="#fn_%s_L%d"><rect class="%s" y="%d" width="1" height="%d" fill="%s"><title>Lines %d-%d: %s</title></rect></a>`,
+			html.EscapeString(f.Anchor), first, class, r.First, r.Last-r.First+1, minimapColors[r.State], first, last, status)
+	}
+	b.WriteString(`</svg>`)
+	return b.String()
+}
@@ -0,0 +1,33 @@
+package themes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMinimapRegions(t *testing.T) {
+	// n: no statement, c: covered, m: missed.
+	lines := func(states string) []functionLine {
+		rv := make([]functionLine, len(states))
+		for i, s := range states {
+			rv[i] = functionLine{LineNumber: i + 1, Covered: s == 'c', Missed: s == 'm'}
+		}
+		return rv
+	}
+	tests := []struct {
+		states string
+		want   []minimapRegion
+	}{
+		{"", nil},
+		{"nnn", []minimapRegion{{0, 2, lineNeutral}}},
+		{"ccnc", []minimapRegion{{0, 3, lineCovered}}},
+		{"nccnmmnc", []minimapRegion{{0, 0, lineNeutral}, {1, 3, lineCovered}, {4, 6, lineMissed}, {7, 7, lineCovered}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.states, func(t *testing.T) {
+			if got := minimapRegions(lines(tt.states)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("minimapRegions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
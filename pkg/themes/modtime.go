@@ -0,0 +1,28 @@
+package themes
+
+import (
+	"os"
+	"time"
+)
+
+// lastModified returns the most recent modification time of the source files
+// of the functions of the package, helpers included. Returns the zero time if
+// none of them can be read.
+func lastModified(rp *reportPackage) time.Time {
+	var rv time.Time
+	seen := make(map[string]bool)
+	check := func(fns reportFunctionList) {
+		for _, f := range fns {
+			if seen[f.File] {
+				continue
+			}
+			seen[f.File] = true
+			if fi, err := os.Stat(f.File); err == nil && fi.ModTime().After(rv) {
+				rv = fi.ModTime()
+			}
+		}
+	}
+	check(rp.Functions)
+	check(rp.helperFunctions())
+	return rv
+}
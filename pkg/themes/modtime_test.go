@@ -0,0 +1,47 @@
+package themes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/axw/gocov"
+)
+
+func TestLastModified(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gocov-html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	old, recent := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	files := map[string]time.Time{"a.go": old, "b.go": recent}
+	for name, mtime := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte("package p\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rp := &reportPackage{Functions: reportFunctionList{
+		{Function: &gocov.Function{Name: "f", File: filepath.Join(dir, "a.go")}},
+		{Function: &gocov.Function{Name: "g", File: filepath.Join(dir, "missing.go")}},
+	}}
+	if got := lastModified(rp); !got.Equal(old) {
+		t.Errorf("lastModified() = %v, want %v", got, old)
+	}
+	rp.Helpers = &reportPackage{Functions: reportFunctionList{
+		{Function: &gocov.Function{Name: "h", File: filepath.Join(dir, "b.go")}},
+	}}
+	if got := lastModified(rp); !got.Equal(recent) {
+		t.Errorf("lastModified() = %v, want %v with helpers", got, recent)
+	}
+	if got := lastModified(&reportPackage{}); !got.IsZero() {
+		t.Errorf("lastModified() = %v, want zero without source files", got)
+	}
+}
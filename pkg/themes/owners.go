@@ -0,0 +1,187 @@
+package themes
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rotisserie/eris"
+)
+
+// codeOwnersRule is a line of a CODEOWNERS file: a pattern matching paths
+// relative to the root of the repository, and its owners.
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// codeOwners holds the rules of a CODEOWNERS file.
+type codeOwners struct {
+	// root is the directory paths are matched relative to.
+	root  string
+	rules []codeOwnersRule
+}
+
+// codeOwnersPattern converts a CODEOWNERS pattern, which follows the gitignore
+// syntax, to a regular expression matching slash separated relative paths.
+func codeOwnersPattern(p string) (*regexp.Regexp, error) {
+	// Patterns with a slash, a trailing one excepted, are relative to the root.
+	// Others match at any depth.
+	anchored := strings.HasPrefix(p, "/") || strings.Contains(strings.TrimSuffix(p, "/"), "/")
+	dir := strings.HasSuffix(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	// A pattern matching a directory matches all the files it contains, at any
+	// depth. Wildcards in the last segment only match its direct entries.
+	last := p[strings.LastIndex(p, "/")+1:]
+	if dir || !strings.ContainsAny(last, "*?") {
+		b.WriteString("(?:/.*)?")
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// parseCodeOwners reads the rules of a CODEOWNERS file. Blank lines and
+// comments are ignored.
+func parseCodeOwners(r io.Reader) ([]codeOwnersRule, error) {
+	var rules []codeOwnersRule
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		re, err := codeOwnersPattern(fields[0])
+		if err != nil {
+			return nil, eris.Wrapf(err, "line %d", n)
+		}
+		rules = append(rules, codeOwnersRule{pattern: re, owners: fields[1:]})
+	}
+	return rules, eris.Wrap(sc.Err(), "read")
+}
+
+// loadCodeOwners reads a CODEOWNERS file. Paths are matched relative to root
+// if set. Otherwise they are relative to the directory of the file, or to its
+// parent for files in the .github or docs directories, like GitHub does.
+func loadCodeOwners(path, root string) (*codeOwners, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, eris.Wrap(err, "open CODEOWNERS")
+	}
+	defer f.Close()
+	rules, err := parseCodeOwners(f)
+	if err != nil {
+		return nil, eris.Wrapf(err, "CODEOWNERS %q", path)
+	}
+	if root == "" {
+		root = filepath.Dir(path)
+		if d := filepath.Base(root); d == ".github" || d == "docs" {
+			root = filepath.Dir(root)
+		}
+	}
+	if root, err = filepath.Abs(root); err != nil {
+		return nil, eris.Wrap(err, "CODEOWNERS root")
+	}
+	return &codeOwners{root: root, rules: rules}, nil
+}
+
+// owners returns the owners of a source file. The last matching rule wins.
+// Returns nil for files without owner.
+func (c *codeOwners) owners(path string) []string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	rel, err := filepath.Rel(c.root, path)
+	if err != nil {
+		return nil
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return nil
+	}
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchString(rel) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// ownerCoverage is the coverage of the statements of all files of an owner.
+type ownerCoverage struct {
+	// Owner is a user, a team or an email. Empty for files without owner.
+	Owner string
+	statementCount
+}
+
+// addOwners counts statements of a file for each of its owners. Files without
+// owner are counted for the empty owner.
+func (rp *reportPackage) addOwners(owners []string, total, reached int) {
+	if len(owners) == 0 {
+		owners = []string{""}
+	}
+	for _, o := range owners {
+		if rp.owners[o] == nil {
+			rp.owners[o] = &ownerCoverage{Owner: o}
+		}
+		rp.owners[o].TotalStatements += total
+		rp.owners[o].ReachedStatements += reached
+	}
+}
+
+// ownerBreakdown sums the statements of all packages per owner of their
+// files. Files with several owners count for each of them. Owners with the
+// most uncovered statements come first.
+func ownerBreakdown(packages reportPackageList) []*ownerCoverage {
+	byOwner := make(map[string]*ownerCoverage)
+	for _, rp := range packages {
+		for owner, o := range rp.owners {
+			if byOwner[owner] == nil {
+				byOwner[owner] = &ownerCoverage{Owner: owner}
+			}
+			byOwner[owner].TotalStatements += o.TotalStatements
+			byOwner[owner].ReachedStatements += o.ReachedStatements
+		}
+	}
+	rv := make([]*ownerCoverage, 0, len(byOwner))
+	for _, o := range byOwner {
+		rv = append(rv, o)
+	}
+	sort.Slice(rv, func(i, j int) bool {
+		if rv[i].UncoveredStatements() != rv[j].UncoveredStatements() {
+			return rv[i].UncoveredStatements() > rv[j].UncoveredStatements()
+		}
+		return rv[i].Owner < rv[j].Owner
+	})
+	return rv
+}
@@ -0,0 +1,138 @@
+package themes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/axw/gocov"
+)
+
+func TestCodeOwnersPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*", "a/b/c.go", true},
+		{"*.go", "a/b/c.go", true},
+		{"*.go", "a/b/c.txt", false},
+		{"/build/", "build/x.go", true},
+		{"/build/", "src/build/x.go", false},
+		{"build/", "src/build/x.go", true},
+		{"pkg/api", "pkg/api/v1/x.go", true},
+		{"pkg/api", "x/pkg/api/v1/x.go", false},
+		{"pkg/*.go", "pkg/x.go", true},
+		{"pkg/*.go", "pkg/sub/x.go", false},
+		{"pkg/**/x.go", "pkg/a/b/x.go", true},
+		{"pkg/**/x.go", "pkg/x.go", true},
+		{"**/gen", "a/b/gen/x.go", true},
+		{"docs/**", "docs/a/b.md", true},
+		{"docs/*", "docs/a.md", true},
+		{"docs/*", "docs/a/b.md", false},
+		{"docs/*/", "docs/a/b.md", true},
+		{"*.go", "a/b.go/c.txt", false},
+		{"x?.go", "xy.go", true},
+		{"a.go", "a_go", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			re, err := codeOwnersPattern(tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := re.MatchString(tt.path); got != tt.want {
+				t.Errorf("%q matches %q: %v, want %v (%s)", tt.pattern, tt.path, got, tt.want, re)
+			}
+		})
+	}
+}
+
+func TestOwnerBreakdown(t *testing.T) {
+	rules, err := parseCodeOwners(strings.NewReader(`
+# Default owners.
+*          @org/core
+/api/      @org/api @alice  # API team
+*.pb.go
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &codeOwners{root: "/repo", rules: rules}
+	fn := func(file string, reached ...int64) *gocov.Function {
+		f := newFunction("f", reached...)
+		f.File = file
+		return f
+	}
+	pct := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name     string
+		cmax     uint8
+		pkgMax   *float64
+		filtered bool
+		want     map[string]statementCount
+	}{
+		{
+			name: "all",
+			cmax: 100,
+			want: map[string]statementCount{
+				"":          {TotalStatements: 5},
+				"@org/core": {TotalStatements: 4, ReachedStatements: 3},
+				"@org/api":  {TotalStatements: 3, ReachedStatements: 2},
+				"@alice":    {TotalStatements: 3, ReachedStatements: 2},
+			},
+		},
+		{
+			// Functions left out of the list still count.
+			name: "function coverage filter",
+			cmax: 50,
+			want: map[string]statementCount{
+				"":          {TotalStatements: 5},
+				"@org/core": {TotalStatements: 4, ReachedStatements: 3},
+				"@org/api":  {TotalStatements: 3, ReachedStatements: 2},
+				"@alice":    {TotalStatements: 3, ReachedStatements: 2},
+			},
+		},
+		{
+			// Like the overview, only the listed packages count.
+			name:     "filtered overview",
+			cmax:     100,
+			pkgMax:   pct(50),
+			filtered: true,
+			want: map[string]statementCount{
+				"":          {TotalStatements: 5},
+				"@org/core": {TotalStatements: 2, ReachedStatements: 1},
+				"@org/api":  {TotalStatements: 3, ReachedStatements: 2},
+				"@alice":    {TotalStatements: 3, ReachedStatements: 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newReport()
+			r.CoverageMax, r.PackageCoverageMax, r.OverviewFiltered = tt.cmax, tt.pkgMax, tt.filtered
+			r.owners = c
+			r.packages = []*gocov.Package{
+				{Name: "p", Functions: []*gocov.Function{
+					fn("/repo/main.go", 1, 0),
+					fn("/repo/api/api.go", 1, 1, 0),
+					fn("/repo/api/api.pb.go", 0, 0, 0, 0),
+					fn("/elsewhere/x.go", 0),
+				}},
+				{Name: "q", Functions: []*gocov.Function{fn("/repo/q/q.go", 1, 1)}},
+			}
+			_, totals := r.listedPackages(buildReportPackages(r))
+			got := make(map[string]statementCount)
+			for _, o := range ownerBreakdown(totals) {
+				got[o.Owner] = o.statementCount
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got owners %v, want %v", got, tt.want)
+			}
+			for owner, w := range tt.want {
+				if got[owner] != w {
+					t.Errorf("owner %q: got %+v, want %+v", owner, got[owner], w)
+				}
+			}
+		})
+	}
+}
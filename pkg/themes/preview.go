@@ -0,0 +1,83 @@
+package themes
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/rotisserie/eris"
+)
+
+// previewTitle is the title of the link previews, the same as the page title.
+const previewTitle = "Coverage Report"
+
+// preview holds the Open Graph and Twitter Card metadata of the report, used
+// by chat apps and social networks to render a rich preview of its link.
+type preview struct {
+	// Title is the title of the preview, naming the package of single package
+	// reports.
+	Title string
+	// Description summarizes the total coverage of the report.
+	Description string
+	// URL is the absolute URL the report is hosted at. Empty if unknown.
+	URL string
+	// Image is the absolute URL of the preview image. Empty if none.
+	Image string
+}
+
+// Card is the kind of Twitter Card to render: a large image if any.
+func (p *preview) Card() string {
+	if p.Image != "" {
+		return "summary_large_image"
+	}
+	return "summary"
+}
+
+// previewImageURL checks the URL of the preview image and resolves it against
+// baseHref if relative, since link previews require absolute URLs.
+func previewImageURL(image, baseHref string) (string, error) {
+	if image == "" {
+		return "", nil
+	}
+	u, err := url.Parse(image)
+	if err != nil {
+		return "", eris.Wrap(err, "preview image")
+	}
+	if u.IsAbs() {
+		return u.String(), nil
+	}
+	base, err := url.Parse(baseHref)
+	if err != nil || !base.IsAbs() {
+		return "", eris.Errorf("preview image %q: relative URL requires an absolute base href", image)
+	}
+	return base.ResolveReference(u).String(), nil
+}
+
+// newPreview returns the link preview of a report of the packages. image must
+// be an absolute URL, or empty for no image.
+func newPreview(packages reportPackageList, baseHref, image, sep string) *preview {
+	total := overview(packages)
+	p := &preview{
+		Title: previewTitle,
+		Description: fmt.Sprintf("Total coverage: %.1f%% (%s/%s statements) of %s.",
+			total.PercentageReached(),
+			formatCount(total.ReachedStatements, sep),
+			formatCount(total.TotalStatements, sep),
+			pluralize(len(packages), "package", sep)),
+	}
+	if len(packages) == 1 {
+		p.Title = fmt.Sprintf("%s: %s", previewTitle, packages[0].Pkg.Name)
+	}
+	if base, err := url.Parse(baseHref); err == nil && base.IsAbs() {
+		p.URL = base.String()
+	}
+	p.Image = image
+	return p
+}
+
+// pluralize returns n followed by noun, with an s if n isn't 1.
+func pluralize(n int, noun, sep string) string {
+	if n != 1 {
+		noun += "s"
+	}
+	return formatCount(n, sep) + " " + noun
+}
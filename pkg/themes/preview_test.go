@@ -0,0 +1,63 @@
+package themes
+
+import (
+	"testing"
+
+	"github.com/axw/gocov"
+)
+
+func TestPreviewImageURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		image   string
+		base    string
+		want    string
+		wantErr bool
+	}{
+		{"none", "", "", "", false},
+		{"absolute", "https://img.example.com/badge.png", "", "https://img.example.com/badge.png", false},
+		{"relative", "badge.png", "https://example.com/reports/42/", "https://example.com/reports/42/badge.png", false},
+		{"root relative", "/badge.png", "https://example.com/reports/42/", "https://example.com/badge.png", false},
+		{"relative without base", "badge.png", "", "", true},
+		{"relative with relative base", "badge.png", "/reports/42/", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := previewImageURL(tt.image, tt.base)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("previewImageURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("previewImageURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPreview(t *testing.T) {
+	packages := reportPackageList{
+		{Pkg: &gocov.Package{Name: "a"}, ReachedStatements: 1500, TotalStatements: 2000},
+		{Pkg: &gocov.Package{Name: "b"}, ReachedStatements: 0, TotalStatements: 500},
+	}
+	p := newPreview(packages, "/reports/42/", "", ",")
+	if p.Title != "Coverage Report" {
+		t.Errorf("got title %q", p.Title)
+	}
+	if want := "Total coverage: 60.0% (1,500/2,500 statements) of 2 packages."; p.Description != want {
+		t.Errorf("got description %q, want %q", p.Description, want)
+	}
+	if p.URL != "" || p.Card() != "summary" {
+		t.Errorf("got URL %q and card %q, want no URL and a summary card", p.URL, p.Card())
+	}
+
+	p = newPreview(packages[:1], "https://example.com/reports/42/", "https://example.com/badge.png", "")
+	if p.Title != "Coverage Report: a" {
+		t.Errorf("got title %q", p.Title)
+	}
+	if want := "Total coverage: 75.0% (1500/2000 statements) of 1 package."; p.Description != want {
+		t.Errorf("got description %q, want %q", p.Description, want)
+	}
+	if p.URL != "https://example.com/reports/42/" || p.Card() != "summary_large_image" {
+		t.Errorf("got URL %q and card %q", p.URL, p.Card())
+	}
+}
@@ -0,0 +1,69 @@
+package themes
+
+import (
+	"sort"
+	"strings"
+)
+
+// functionGroup is a set of functions of a package sharing the same receiver
+// type, with their own totals.
+type functionGroup struct {
+	// Receiver is the receiver type of the methods of the group, like "T".
+	// Empty for the package-level functions.
+	Receiver string
+	// Functions is the list of functions of the group, in report order.
+	Functions reportFunctionList
+	statementCount
+}
+
+// Label is the title of the group in the report.
+func (g *functionGroup) Label() string {
+	if g.Receiver == "" {
+		return "package-level"
+	}
+	return "type " + g.Receiver
+}
+
+// receiverName returns the receiver type of the function named name by gocov,
+// like "T" for "T.M" or "(*T).M", without type parameters. Returns an empty
+// string for functions and function literals.
+func receiverName(name string) string {
+	i := strings.LastIndex(name, ".")
+	if i < 0 || strings.HasPrefix(name, "@") {
+		return ""
+	}
+	recv := strings.Trim(name[:i], "(*)")
+	if j := strings.Index(recv, "["); j >= 0 {
+		recv = recv[:j]
+	}
+	return recv
+}
+
+// groupFunctions reorders the functions by receiver type, package-level
+// functions first, then types by name, keeping the report order within a
+// type. Returns the groups, sharing the functions. Not applicable functions
+// are left out of the totals of their group.
+func groupFunctions(fns reportFunctionList) []*functionGroup {
+	sort.SliceStable(fns, func(i, j int) bool {
+		return receiverName(fns[i].Name) < receiverName(fns[j].Name)
+	})
+	var groups []*functionGroup
+	first := 0
+	for i := range fns {
+		recv := receiverName(fns[i].Name)
+		if i+1 < len(fns) && receiverName(fns[i+1].Name) == recv {
+			continue
+		}
+		g := &functionGroup{Receiver: recv, Functions: fns[first : i+1]}
+		for _, f := range g.Functions {
+			if f.NotApplicable {
+				continue
+			}
+			g.TotalStatements += len(f.Statements)
+			g.ReachedStatements += f.StatementsReached
+		}
+		groups = append(groups, g)
+		first = i + 1
+	}
+	return groups
+}
@@ -0,0 +1,72 @@
+package themes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/axw/gocov"
+)
+
+func TestReceiverName(t *testing.T) {
+	tests := map[string]string{
+		"F":          "",
+		"@15:9":      "",
+		"T.M":        "T",
+		"(*T).M":     "T",
+		"List[T].At": "List",
+	}
+	for name, want := range tests {
+		if got := receiverName(name); got != want {
+			t.Errorf("receiverName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestGroupFunctions(t *testing.T) {
+	fn := func(name string, reached, total int, na bool) reportFunction {
+		return reportFunction{
+			Function:          &gocov.Function{Name: name, Statements: make([]*gocov.Statement, total)},
+			StatementsReached: reached,
+			NotApplicable:     na,
+		}
+	}
+	fns := reportFunctionList{
+		fn("T.B", 1, 2, false),
+		fn("New", 1, 1, false),
+		fn("S.A", 0, 3, false),
+		fn("T.A", 2, 2, false),
+		fn("stub", 0, 4, true),
+	}
+	groups := groupFunctions(fns)
+
+	var got [][]string
+	for _, g := range groups {
+		names := []string{g.Label()}
+		for _, f := range g.Functions {
+			names = append(names, f.Name)
+		}
+		got = append(got, names)
+	}
+	want := [][]string{
+		{"package-level", "New", "stub"},
+		{"type S", "S.A"},
+		{"type T", "T.B", "T.A"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got groups %v, want %v", got, want)
+	}
+	if fns[0].Name != "New" || fns[4].Name != "T.A" {
+		t.Error("functions not sorted by group")
+	}
+	if c := groups[0].statementCount; c.ReachedStatements != 1 || c.TotalStatements != 1 {
+		t.Errorf("package-level statements = %d/%d, want 1/1", c.ReachedStatements, c.TotalStatements)
+	}
+	if c := groups[2].statementCount; c.ReachedStatements != 3 || c.TotalStatements != 4 {
+		t.Errorf("type T statements = %d/%d, want 3/4", c.ReachedStatements, c.TotalStatements)
+	}
+
+	rp := &reportPackage{Functions: fns, TotalStatements: 8, ReachedStatements: 4}
+	if g := rp.FunctionGroups(); len(g) != 1 || len(g[0].Functions) != 5 || g[0].TotalStatements != 8 {
+		t.Errorf("got %d groups without grouping, want a single group of all functions", len(g))
+	}
+}
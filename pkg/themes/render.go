@@ -0,0 +1,133 @@
+package themes
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"text/template"
+
+	"github.com/axw/gocov"
+	"github.com/rotisserie/eris"
+)
+
+// sectionTemplate is the name of the template rendering the section of a
+// single package. Themes without it are always rendered as a whole.
+const sectionTemplate = "package"
+
+// Renderer writes reports like WriteReportCoverage but renders incrementally:
+// the HTML sections of packages whose coverage data and source files did not
+// change since its previous report are reused as is. It speeds up processes
+// rendering the same report again and again, like the serve mode for every
+// request. Safe for concurrent use.
+type Renderer struct {
+	mu sync.Mutex
+	// sections holds the sections of the previous report, per package name.
+	sections map[string]renderedSection
+	// quiet disables the messages written to stderr while rendering, like the
+	// rendering time, for the renders of every request of a Handler.
+	quiet bool
+}
+
+// renderedSection is the HTML section of a package and the hash of all the
+// inputs it was rendered from.
+type renderedSection struct {
+	hash string
+	html string
+}
+
+// NewRenderer returns a renderer without any previous report.
+func NewRenderer() *Renderer {
+	return &Renderer{sections: make(map[string]renderedSection)}
+}
+
+// WriteReportCoverage writes the report to w, reusing the unchanged package
+// sections of the previous report.
+func (rd *Renderer) WriteReportCoverage(w io.Writer, r io.Reader, opts ReportOptions) error {
+	return writeReportCoverage(w, r, opts, rd)
+}
+
+// sectionHash returns a hash of everything the section of a package depends
+// on: the theme, the report options, the coverage data of the package, its
+// coverage trend and the size and modification time of its source files.
+// Options only changing how or where the report is written, like Gzip, are
+// left out.
+func sectionHash(theme string, opts ReportOptions, pkg *gocov.Package, trend string) (string, error) {
+	opts.Gzip, opts.GateResult = false, ""
+	opts.Header, opts.HeaderFile = false, ""
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	for _, v := range []interface{}{theme, opts, pkg, trend} {
+		if err := enc.Encode(v); err != nil {
+			return "", eris.Wrap(err, "hash section")
+		}
+	}
+	files := make(map[string]bool)
+	for _, fn := range pkg.Functions {
+		files[fn.File] = true
+	}
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if fi, err := os.Stat(path); err == nil {
+			fmt.Fprintf(h, "%s %d %d\n", path, fi.Size(), fi.ModTime().UnixNano())
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// execute renders the template of the theme like a full render would, but
+// only renders the sections of the packages that changed.
+func (rd *Renderer) execute(w io.Writer, theme Beautifier, data *templateData, opts ReportOptions) error {
+	tmpl := theme.Template()
+	if tmpl.Lookup(sectionTemplate) == nil {
+		return eris.Wrap(tmpl.Execute(w, data), "execute template")
+	}
+	rd.mu.Lock()
+	prev := rd.sections
+	rd.mu.Unlock()
+
+	next := make(map[string]renderedSection)
+	section := func(s packageSection) (string, error) {
+		name := s.Package.Pkg.Name
+		hash, err := sectionHash(theme.Name(), opts, s.Package.Pkg, s.Package.Trend)
+		if err != nil {
+			return "", err
+		}
+		if c, ok := prev[name]; ok && c.hash == hash {
+			next[name] = c
+			return c.html, nil
+		}
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, sectionTemplate, s); err != nil {
+			return "", eris.Wrapf(err, "package %s", name)
+		}
+		next[name] = renderedSection{hash: hash, html: buf.String()}
+		return buf.String(), nil
+	}
+	// The page is rendered with a copy of the template whose package sections
+	// are taken from the previous report or rendered apart.
+	page, err := tmpl.Clone()
+	if err != nil {
+		return eris.Wrap(err, "clone template")
+	}
+	page.Funcs(template.FuncMap{"renderedSection": section})
+	if _, err := page.New(sectionTemplate).Parse(`{{renderedSection .}}`); err != nil {
+		return eris.Wrap(err, "parse section template")
+	}
+	if err := page.Execute(w, data); err != nil {
+		return eris.Wrap(err, "execute template")
+	}
+	rd.mu.Lock()
+	rd.sections = next
+	rd.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,76 @@
+package themes
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRendererWriteReportCoverage(t *testing.T) {
+	pkg := func(name string, reached ...string) string {
+		return `{"Name":"` + name + `","Functions":[{"Name":"f","Statements":[{"Reached":` + strings.Join(reached, `},{"Reached":`) + `}]}]}`
+	}
+	data := func(packages ...string) string {
+		return `{"Packages":[` + strings.Join(packages, ",") + `]}`
+	}
+	// The generation date may change between renders.
+	when := regexp.MustCompile(`Generated on [^<]*`)
+	render := func(t *testing.T, rd *Renderer, data string) string {
+		var buf bytes.Buffer
+		opts := ReportOptions{CoverageMax: 100, MinHits: 1, HideSource: true}
+		var err error
+		if rd == nil {
+			err = WriteReportCoverage(&buf, strings.NewReader(data), opts)
+		} else {
+			err = rd.WriteReportCoverage(&buf, strings.NewReader(data), opts)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return when.ReplaceAllString(buf.String(), "")
+	}
+	for _, th := range List() {
+		t.Run(th.Name(), func(t *testing.T) {
+			defer func(old Beautifier) { curTheme = old }(curTheme)
+			curTheme = th
+			rd := NewRenderer()
+			runs := []string{
+				data(pkg("a", "1", "0"), pkg("b", "0")),
+				data(pkg("a", "1", "0"), pkg("b", "1")),
+				data(pkg("a", "1", "0"), pkg("b", "1"), pkg("c", "0", "0")),
+				data(pkg("c", "0", "1")),
+			}
+			for i, run := range runs {
+				if got, want := render(t, rd, run), render(t, nil, run); got != want {
+					t.Fatalf("run %d: incremental render differs from a full render:\n%s\nwant:\n%s", i, got, want)
+				}
+			}
+			if len(rd.sections) != 1 {
+				t.Errorf("got %d sections, want sections of removed packages dropped", len(rd.sections))
+			}
+
+			// Unchanged sections are not rendered again.
+			c := rd.sections["c"]
+			c.html = "<!-- reused -->"
+			rd.sections["c"] = c
+			if got := render(t, rd, runs[3]); !strings.Contains(got, c.html) {
+				t.Error("unchanged section was rendered again")
+			}
+
+			// Neither are they when only the compression of the report changes,
+			// like for the requests of clients with and without gzip support.
+			var buf bytes.Buffer
+			opts := ReportOptions{CoverageMax: 100, MinHits: 1, HideSource: true, Gzip: true}
+			if err := rd.WriteReportCoverage(&buf, strings.NewReader(runs[3]), opts); err != nil {
+				t.Fatal(err)
+			}
+			if rd.sections["c"].html != c.html {
+				t.Error("section was rendered again for a compressed report")
+			}
+			if got := render(t, rd, runs[3]); !strings.Contains(got, c.html) {
+				t.Error("section was rendered again for an uncompressed report")
+			}
+		})
+	}
+}
@@ -1,15 +1,18 @@
 package themes
 
 import (
+	"compress/gzip"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"go/token"
 	"html"
 	"io"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -29,11 +32,229 @@ type ReportOptions struct {
 	CoverageMin uint8
 	// CoverageMax filters out all functions whose code coverage is greater than it is.
 	CoverageMax uint8
+	// PackageCoverageMin filters out all packages whose code coverage is smaller
+	// than it is.
+	PackageCoverageMin float64
+	// PackageCoverageMax filters out all packages whose code coverage is greater
+	// than it is. Ignored if nil; zero only keeps the packages without any
+	// covered statement.
+	PackageCoverageMax *float64
+	// OverviewFiltered computes the overview over the packages left by the
+	// package coverage filters instead of all packages. The coverage gate always
+	// applies to all packages.
+	OverviewFiltered bool
+	// MinHits is the number of times a statement must be reached to be considered
+	// covered. Values lower than 1 mean a single hit is enough. Since it changes which
+	// statements are covered, it affects all coverage percentages of the report.
+	MinHits int64
+	// GateTotal is the minimum total coverage percentage required by the coverage
+	// gate. Zero disables the rule.
+	GateTotal float64
+	// GatePackage is the minimum coverage percentage required for every package by
+	// the coverage gate. Zero disables the rule.
+	GatePackage float64
+	// InputOrder renders packages in the order they first appear in the coverage
+	// data, instead of sorting them by name.
+	InputOrder bool
+	// MergeUnion merges packages found several times in the coverage data, e.g.
+	// when combining CI shards, by keeping the highest hit count of every statement
+	// instead of summing them. A statement is then covered if any shard covered it.
+	MergeUnion bool
+	// Format is the output format of the report, one of the Format* constants.
+	// Defaults to FormatHTML if empty.
+	Format string
+	// Gzip compresses the report with gzip.
+	Gzip bool
+	// DigestCount is the number of packages written by the digest format. All
+	// packages are written if zero.
+	DigestCount int
+	// DigestDescending sorts the digest format by decreasing coverage, best
+	// packages first.
+	DigestDescending bool
+	// PathBase is the directory source file paths are relative to in machine readable
+	// formats. Paths are absolute if empty.
+	PathBase string
+	// Symbols is the set of glyphs marking covered and uncovered functions in
+	// the text format: "ascii" (+ and -, the default), "unicode" (check and
+	// cross marks) or a custom "covered,uncovered" pair.
+	Symbols string
+	// MarkdownCollapsible collapses every package of the markdown format in a
+	// details element, whose summary is the coverage of the package, to keep
+	// pull request comments compact.
+	MarkdownCollapsible bool
+	// Header adds a summary (generation time, tool version and total coverage) at
+	// the top of non-HTML outputs, as comments. Formats without a comment syntax,
+	// like ndjson, are kept strict: the summary is written to HeaderFile instead.
+	Header bool
+	// HeaderFile is the path to the sidecar file holding the summary header of
+	// formats without a comment syntax.
+	HeaderFile string
+	// CopyCommand renders a button copying the command used to generate the report
+	// to the clipboard.
+	CopyCommand bool
+	// HeadHTML is a raw HTML snippet added to the <head> of the page, like analytics
+	// tags or custom meta. It is NOT escaped: only use trusted content.
+	HeadHTML string
+	// BaseHref is the URL the report is hosted at, prefixed to the links to other
+	// pages, like the reports of an index. In-page links are left alone. It can
+	// be absolute ("https://example.com/reports/run-42/") or relative to the host
+	// ("/reports/run-42/"). A trailing slash is added if missing.
+	BaseHref string
+	// Preview renders Open Graph and Twitter Card metadata, so that links to a
+	// hosted report get a rich preview with the total coverage in chat apps.
+	// The page URL is only known with an absolute BaseHref.
+	Preview bool
+	// PreviewImage is the URL of the image of the link preview, like a coverage
+	// badge. A relative URL is resolved against BaseHref, which must then be
+	// absolute. No image is used if empty.
+	PreviewImage string
+	// File restricts the report to the functions of a single source file. It can
+	// be an absolute path or a path relative to any parent directory of the file,
+	// like "pkg/themes/report.go". It must match a single file.
+	File string
+	// Focus is a regular expression restricting the report to the functions whose
+	// qualified name, like "github.com/user/pkg.T.Method", matches it. Matching
+	// functions are also listed together, whatever their package.
+	Focus string
+	// ThousandsSeparator is inserted between groups of thousands of statement and
+	// function counts, like "," for "124,831". No separator is used if empty.
+	ThousandsSeparator string
+	// Gauge renders the total coverage as a circular gauge.
+	Gauge bool
+	// LargeFunctions is the number of statements from which functions are
+	// reported as too large, whatever their coverage, to nudge teams into
+	// refactoring them. Zero disables the check.
+	LargeFunctions int
+	// LargeFunctionsOutput is where large functions are reported: "report"
+	// (the default) renders them in a section of the HTML report, "stderr"
+	// writes lint-style warnings to stderr, "both" does both.
+	LargeFunctionsOutput string
+	// Exported also reports the coverage of the exported functions and methods
+	// of every package, the public API, along with the coverage of all its
+	// functions.
+	Exported bool
+	// LastModified renders the most recent modification time of the source files
+	// of every package, to spot the packages changing a lot. Requires the source
+	// files.
+	LastModified bool
+	// Minimap renders a strip next to the source of long functions, summarizing
+	// their covered and missed regions. Clicking a region scrolls to it. Has no
+	// effect with HideSource.
+	Minimap bool
+	// History is a directory of summaries of previous runs, written with the
+	// NDJSON format to files with the ".ndjson" extension. The coverage of
+	// every package over the last HistoryLength runs, in file name order, is
+	// rendered as a sparkline next to its current coverage. Nothing is
+	// rendered if the directory can't be read.
+	History string
+	// HistoryLength is the number of summaries of History to render. Defaults
+	// to 10 if zero.
+	HistoryLength int
+	// CodeOwners is the path to a CODEOWNERS file. If set, the coverage of
+	// statements is also rendered per owner of the source files. Paths are
+	// matched relative to PathBase if set.
+	CodeOwners string
+	// Tabs renders every package in its own tab of the HTML page, the first tab
+	// being the overview.
+	Tabs bool
+	// Extensions renders a breakdown of the statements coverage by file
+	// extension, like ".go" and ".pb.go".
+	Extensions bool
+	// Signatures renders the full signature of functions (parameters and results)
+	// instead of their name only. Requires the source files, functions whose source
+	// can't be parsed fall back to their name.
+	Signatures bool
+	// SkipEmptyFunctions leaves functions without any statement out of the function
+	// counts of packages, i.e. TotalFunctions, CoveredFunctions and the average
+	// function coverage. They are still listed in the report, as not applicable.
+	SkipEmptyFunctions bool
+	// NotApplicable is a regular expression matching the paths of source files that
+	// can't be covered, like generated assembly stubs or cgo wrappers. Their functions
+	// are listed in the report as not applicable (N/A) and are left out of all totals:
+	// statements, function counts and coverage gate.
+	NotApplicable string
+	// DiscardReached is a regular expression matching the paths of source files
+	// whose statements are counted as not reached, whatever the coverage data
+	// says. Their functions are still part of all totals. It gives the
+	// coverage of the other files, like the self-coverage of a package whose
+	// code is mostly reached through the tests of another package.
+	//
+	// The coverage data only holds the number of hits of every statement,
+	// summed over all the tests of the run: the contribution of a single test
+	// package can't be told apart. Discarding applies to the files of the
+	// covered code, not of the tests that reached it. To exclude the
+	// contribution of a test package, run it apart and compare the reports,
+	// or run the tests of a package alone, without -coverpkg.
+	DiscardReached string
+	// Helpers is a regular expression matching the qualified name or the file
+	// path of functions that are not production code, like test helpers living
+	// in non-test files. They are listed in a separate section of their package
+	// with their own totals, left out of the package totals.
+	Helpers string
+	// EmptyFilesNotApplicable also considers files without any statement as not
+	// applicable.
+	EmptyFilesNotApplicable bool
+	// RedactPrefix is removed from the file paths displayed in the report.
+	RedactPrefix string
+	// RedactPaths only displays the base name of files in the report.
+	RedactPaths bool
+	// HideSource leaves the source code of functions out of the report.
+	HideSource bool
+	// GroupByReceiver sorts the functions of every package by receiver type,
+	// as derived from their name, and renders them in groups with their own
+	// totals. Package-level functions come first.
+	GroupByReceiver bool
+	// LazySource renders the source of every function collapsed, and only when
+	// expanded, from data embedded in the page: the initial page is much
+	// lighter for large packages. Requires javascript: browsers without it show
+	// no source, unless LazySourceNoScript is set. Disables Minimap.
+	LazySource bool
+	// LazySourceNoScript renders the full source of functions too, for
+	// browsers without javascript, when LazySource is set. The page gets as
+	// heavy as without LazySource for them.
+	LazySourceNoScript bool
+	// Baseline is the path to coverage data generated by axw/gocov for a previous
+	// run, used by the coverage gate for comparison.
+	Baseline string
+	// BaselineGit is a git object holding the baseline coverage data, like
+	// "origin/main:coverage.json", read with "git show". Can't be used along with
+	// Baseline.
+	BaselineGit string
+	// GateNewFunctions enables a coverage gate rule that requires a Baseline. The
+	// rule fails for every function whose coverage decreased since the baseline and
+	// for every new function whose coverage is smaller than GateNewFunctions.
+	// Zero disables the rule.
+	GateNewFunctions float64
+	// GateStatements enables a coverage gate rule that requires a Baseline. The
+	// rule fails if the total number of statements decreased by more than
+	// GateStatementsTolerance percent since the baseline: deleted tests or code
+	// can hide a coverage regression.
+	GateStatements bool
+	// GateStatementsTolerance is the percentage of the statements of the
+	// baseline that can be removed without failing the statements rule.
+	GateStatementsTolerance float64
+	// GateResult is the path to a JSON file describing the outcome of every rule
+	// of the coverage gate. Nothing is written if empty or if no gate rule is set.
+	GateResult string
 }
 
 type report struct {
 	ReportOptions
 	packages []*gocov.Package
+	// inputOrder is the position of every package name in the coverage data.
+	inputOrder map[string]int
+	// baseline holds the packages of the baseline coverage data, if any.
+	baseline []*gocov.Package
+	// gate is the verdict of the coverage gate, nil if no gate rule is set.
+	gate *gateResult
+	// naFiles matches the paths of files that can't be covered.
+	naFiles *regexp.Regexp
+	// helpers matches the functions of the helpers section.
+	helpers *regexp.Regexp
+	// owners holds the rules of the CODEOWNERS file, if any.
+	owners *codeOwners
+	// renderer renders the HTML report incrementally, if set.
+	renderer *Renderer
 }
 
 func unmarshalJSON(data []byte) (packages []*gocov.Package, err error) {
@@ -45,6 +266,98 @@ func unmarshalJSON(data []byte) (packages []*gocov.Package, err error) {
 	return
 }
 
+// normalizeBaseHref checks that href is a valid URL and makes sure it ends
+// with a slash, so that relative links resolve inside the directory.
+func normalizeBaseHref(href string) (string, error) {
+	if href == "" {
+		return "", nil
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", eris.Wrap(err, "base href")
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return "", eris.Errorf("base href %q: query and fragment not allowed", href)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return u.String(), nil
+}
+
+// sameFile reports whether path refers to the file name, which is either a
+// full path or a trailing part of it.
+func sameFile(path, name string) bool {
+	path = filepath.ToSlash(filepath.Clean(path))
+	name = filepath.ToSlash(filepath.Clean(name))
+	return path == name || strings.HasSuffix(path, "/"+name)
+}
+
+// filterFunctions only keeps the functions for which keep returns true.
+// Packages without any remaining function are removed.
+func filterFunctions(packages []*gocov.Package, keep func(*gocov.Package, *gocov.Function) bool) []*gocov.Package {
+	kept := make([]*gocov.Package, 0)
+	for _, pkg := range packages {
+		fns := make([]*gocov.Function, 0)
+		for _, fn := range pkg.Functions {
+			if keep(pkg, fn) {
+				fns = append(fns, fn)
+			}
+		}
+		if len(fns) > 0 {
+			pkg.Functions = fns
+			kept = append(kept, pkg)
+		}
+	}
+	return kept
+}
+
+// filterFile only keeps the functions defined in the file name. Returns an
+// error listing the matching files if name refers to more than one file, like
+// a base name shared by files of several packages.
+func filterFile(packages []*gocov.Package, name string) ([]*gocov.Package, error) {
+	files := make(map[string]bool)
+	packages = filterFunctions(packages, func(_ *gocov.Package, fn *gocov.Function) bool {
+		if !sameFile(fn.File, name) {
+			return false
+		}
+		files[filepath.Clean(fn.File)] = true
+		return true
+	})
+	if len(files) > 1 {
+		matches := make([]string, 0, len(files))
+		for f := range files {
+			matches = append(matches, f)
+		}
+		sort.Strings(matches)
+		return nil, eris.Errorf("file %q is ambiguous, matches %s", name, strings.Join(matches, ", "))
+	}
+	return packages, nil
+}
+
+// filterFocus only keeps the functions whose qualified name, like
+// "github.com/user/pkg.T.Method", matches re.
+func filterFocus(packages []*gocov.Package, re *regexp.Regexp) []*gocov.Package {
+	return filterFunctions(packages, func(pkg *gocov.Package, fn *gocov.Function) bool {
+		return re.MatchString(pkg.Name + "." + fn.Name)
+	})
+}
+
+// discardReached resets the hit count of the statements of the functions
+// whose file path matches re, so that they count as not reached.
+func discardReached(packages []*gocov.Package, re *regexp.Regexp) {
+	for _, pkg := range packages {
+		for _, fn := range pkg.Functions {
+			if !re.MatchString(fn.File) {
+				continue
+			}
+			for _, st := range fn.Statements {
+				st.Reached = 0
+			}
+		}
+	}
+}
+
 type reverse struct {
 	sort.Interface
 }
@@ -59,18 +372,112 @@ func newReport() (r *report) {
 	return
 }
 
-// AddPackage adds a package's coverage information to the report.
-func (r *report) addPackage(p *gocov.Package) {
+// AddPackage adds a package's coverage information to the report. Packages
+// with the same name, like the ones of unit and integration test runs, are
+// merged into one. Returns an error if the statements of a function conflict
+// between the runs, like for runs of different versions of the source.
+func (r *report) addPackage(p *gocov.Package) error {
 	i := sort.Search(len(r.packages), func(i int) bool {
 		return r.packages[i].Name >= p.Name
 	})
 	if i < len(r.packages) && r.packages[i].Name == p.Name {
-		r.packages[i].Accumulate(p)
-	} else {
-		head := r.packages[:i]
-		tail := append([]*gocov.Package{p}, r.packages[i:]...)
-		r.packages = append(head, tail...)
+		return mergePackage(r.packages[i], p, r.MergeUnion)
+	}
+	// Functions listed twice in the package are merged too.
+	merged := &gocov.Package{Name: p.Name}
+	if err := mergePackage(merged, p, r.MergeUnion); err != nil {
+		return err
+	}
+	head := r.packages[:i]
+	tail := append([]*gocov.Package{merged}, r.packages[i:]...)
+	r.packages = append(head, tail...)
+	if r.inputOrder == nil {
+		r.inputOrder = make(map[string]int)
+	}
+	r.inputOrder[p.Name] = len(r.inputOrder)
+	return nil
+}
+
+// functionKey identifies a function of a package across coverage runs.
+type functionKey struct {
+	name, file string
+	start, end int
+}
+
+// mergePackage merges the coverage of p2 into p. Functions are matched by name
+// and position, so that runs covering different functions of the package can
+// be merged, and functions only found in p2 are added to p. Hit counts of
+// statements are summed, or the highest one is kept if union is true.
+func mergePackage(p, p2 *gocov.Package, union bool) error {
+	fns := make(map[functionKey]*gocov.Function, len(p.Functions))
+	for _, f := range p.Functions {
+		fns[functionKey{f.Name, f.File, f.Start, f.End}] = f
+	}
+	for _, f2 := range p2.Functions {
+		k := functionKey{f2.Name, f2.File, f2.Start, f2.End}
+		f, ok := fns[k]
+		if !ok {
+			p.Functions = append(p.Functions, f2)
+			fns[k] = f2
+			continue
+		}
+		if err := mergeStatements(f, f2, union); err != nil {
+			return eris.Wrapf(err, "package %q: function %q", p.Name, f.Name)
+		}
+	}
+	return nil
+}
+
+// mergeStatements merges the hit counts of the statements of f2 into the ones
+// of f, by position if both functions don't have the same statements. Returns
+// an error if a statement of f2 overlaps a different statement of f.
+func mergeStatements(f, f2 *gocov.Function, union bool) error {
+	merge := func(st, st2 *gocov.Statement) {
+		if !union {
+			st.Reached += st2.Reached
+		} else if st2.Reached > st.Reached {
+			st.Reached = st2.Reached
+		}
+	}
+	if sameSpans(f.Statements, f2.Statements) {
+		for i, st := range f.Statements {
+			merge(st, f2.Statements[i])
+		}
+		return nil
+	}
+	type span struct{ start, end int }
+	stmts := make(map[span]*gocov.Statement, len(f.Statements))
+	for _, st := range f.Statements {
+		stmts[span{st.Start, st.End}] = st
+	}
+	var added []*gocov.Statement
+	for _, st2 := range f2.Statements {
+		if st, ok := stmts[span{st2.Start, st2.End}]; ok {
+			merge(st, st2)
+			continue
+		}
+		for _, st := range f.Statements {
+			if st.Start < st2.End && st2.Start < st.End {
+				return eris.Errorf("statement at %d-%d conflicts with %d-%d", st2.Start, st2.End, st.Start, st.End)
+			}
+		}
+		added = append(added, st2)
+	}
+	f.Statements = append(f.Statements, added...)
+	return nil
+}
+
+// sameSpans reports whether both lists of statements have the same positions.
+func sameSpans(s1, s2 []*gocov.Statement) bool {
+	if len(s1) != len(s2) {
+		return false
+	}
+	for i, st := range s1 {
+		if st.Start != s2[i].Start || st.End != s2[i].End {
+			return false
+		}
 	}
+	return true
 }
 
 // Clear clears the coverage information from the report.
@@ -78,37 +485,253 @@ func (r *report) clear() {
 	r.packages = nil
 }
 
+// displayPath returns the path of a source file as shown in the report,
+// redacted according to the report options.
+func (r *report) displayPath(path string) string {
+	if r.RedactPaths {
+		return filepath.Base(path)
+	}
+	if r.RedactPrefix != "" && strings.HasPrefix(path, r.RedactPrefix) {
+		return strings.TrimLeft(strings.TrimPrefix(path, r.RedactPrefix), "/\\")
+	}
+	return path
+}
+
+// redactedValue replaces the secrets of the command line shown in the report.
+const redactedValue = "REDACTED"
+
+// redactArgs returns the command line arguments as shown in the report, with
+// the file paths redacted according to the report options. The value of the
+// -redact flag is left out, as it is the very prefix to hide. Credentials,
+// like the value of the -auth flag or the user info of URLs, are always
+// redacted.
+func (r *report) redactArgs(args []string) []string {
+	rv := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch name := strings.TrimLeft(arg, "-"); {
+		case arg != name && name == "redact":
+			// Skip the value too.
+			i++
+			continue
+		case arg != name && strings.HasPrefix(name, "redact="):
+			continue
+		case arg != name && name == "auth":
+			rv = append(rv, arg)
+			if i++; i < len(args) {
+				rv = append(rv, redactedValue)
+			}
+			continue
+		case arg != name && strings.HasPrefix(name, "auth="):
+			rv = append(rv, strings.TrimSuffix(arg, name)+"auth="+redactedValue)
+			continue
+		}
+		// Paths can be flag values, like -baseline=path, or named inputs,
+		// like name=path.
+		var key string
+		if j := strings.Index(arg, "="); j >= 0 {
+			key, arg = arg[:j+1], arg[j+1:]
+		}
+		if u, err := url.Parse(arg); err == nil && u.Host != "" && u.User != nil {
+			u.User = nil
+			arg = u.String()
+		}
+		if strings.ContainsAny(arg, "/\\") {
+			arg = r.displayPath(arg)
+		}
+		rv = append(rv, key+arg)
+	}
+	return rv
+}
+
+// notApplicable reports whether a source file can't be covered by tests, given
+// its path and number of statements.
+func (r *report) notApplicable(path string, statements int) bool {
+	if r.EmptyFilesNotApplicable && statements == 0 {
+		return true
+	}
+	return r.naFiles != nil && r.naFiles.MatchString(path)
+}
+
+// isHelper returns true if the function matches the helpers option, either by
+// its qualified name or by its file path.
+func (r *report) isHelper(pkg *gocov.Package, fn *gocov.Function) bool {
+	if r.helpers == nil {
+		return false
+	}
+	return r.helpers.MatchString(pkg.Name+"."+fn.Name) || r.helpers.MatchString(fn.File)
+}
+
 func buildReportPackage(pkg *gocov.Package, r *report) reportPackage {
 	rv := reportPackage{
-		Pkg:       pkg,
-		Functions: make(reportFunctionList, 0),
+		Pkg:        pkg,
+		Functions:  make(reportFunctionList, 0),
+		extensions: make(map[string]*extensionCoverage),
+	}
+	if r.Exported {
+		rv.Exported = &statementCount{}
+	}
+	if r.owners != nil {
+		rv.owners = make(map[string]*ownerCoverage)
+	}
+	// Signatures of functions per file.
+	sigs := make(map[string]map[int]string)
+	// Number of statements per file.
+	fileStatements := make(map[string]int)
+	// Number of functions per name.
+	names := make(map[string]int)
+	for _, fn := range pkg.Functions {
+		fileStatements[fn.File] += len(fn.Statements)
 	}
 	for _, fn := range pkg.Functions {
 		reached := 0
 		for _, stmt := range fn.Statements {
-			if stmt.Reached > 0 {
+			if covered(stmt, r.MinHits) {
 				reached++
 			}
 		}
-		rf := reportFunction{Function: fn, StatementsReached: reached}
+		rf := reportFunction{
+			Function:          fn,
+			StatementsReached: reached,
+			DisplayFile:       r.displayPath(fn.File),
+			ID:                fn.Name,
+			minHits:           r.MinHits,
+		}
+		if n := names[fn.Name]; n > 0 {
+			rf.ID = fmt.Sprintf("%s-%d", fn.Name, n+1)
+		}
+		rf.Anchor = pkg.Name + ":" + rf.ID
+		names[fn.Name]++
+		if r.Signatures {
+			if _, ok := sigs[fn.File]; !ok {
+				// Missing source files are just cached as nil.
+				sigs[fn.File], _ = fileSignatures(fn.File)
+			}
+			rf.Signature = sigs[fn.File][fn.Start]
+		}
+		if r.notApplicable(fn.File, fileStatements[fn.File]) {
+			rf.NotApplicable = true
+		} else if r.SkipEmptyFunctions && len(fn.Statements) == 0 {
+			rf.NotApplicable = true
+		}
+		// Helpers are counted apart from the package.
+		target := &rv
+		if r.isHelper(pkg, fn) {
+			if rv.Helpers == nil {
+				rv.Helpers = &reportPackage{Pkg: pkg, Functions: make(reportFunctionList, 0)}
+			}
+			target = rv.Helpers
+		}
 		covp := rf.CoveragePercent()
 		if covp >= float64(r.CoverageMin) && covp <= float64(r.CoverageMax) {
-			rv.Functions = append(rv.Functions, rf)
+			target.Functions = append(target.Functions, rf)
+		}
+		if rf.NotApplicable {
+			continue
+		}
+		target.TotalFunctions++
+		target.functionCoverageSum += covp
+		if reached == len(fn.Statements) {
+			target.CoveredFunctions++
+		}
+		target.TotalStatements += len(fn.Statements)
+		target.ReachedStatements += reached
+		if target != &rv {
+			continue
+		}
+		ext := fileExtension(fn.File)
+		if rv.extensions[ext] == nil {
+			rv.extensions[ext] = &extensionCoverage{Extension: ext}
+		}
+		rv.extensions[ext].TotalStatements += len(fn.Statements)
+		rv.extensions[ext].ReachedStatements += reached
+		if rv.owners != nil {
+			rv.addOwners(r.owners.owners(fn.File), len(fn.Statements), reached)
+		}
+		if rv.Exported != nil && isExported(fn.Name) {
+			rv.Exported.TotalStatements += len(fn.Statements)
+			rv.Exported.ReachedStatements += reached
 		}
-		rv.TotalStatements += len(fn.Statements)
-		rv.ReachedStatements += reached
 	}
-	if r.LowCoverageOnTop {
-		sort.Sort(rv.Functions)
-	} else {
-		sort.Sort(reverse{rv.Functions})
+	for _, fns := range []reportFunctionList{rv.Functions, rv.helperFunctions()} {
+		if r.LowCoverageOnTop {
+			sort.Sort(fns)
+		} else {
+			sort.Sort(reverse{fns})
+		}
+	}
+	if r.GroupByReceiver {
+		rv.Groups = groupFunctions(rv.Functions)
+	}
+	return rv
+}
+
+// listedPackages returns the packages whose coverage is within the package
+// coverage range, and the packages the overview is computed from: all of them
+// unless the OverviewFiltered option is set.
+func (r *report) listedPackages(packages reportPackageList) (listed, totals reportPackageList) {
+	if r.PackageCoverageMin <= 0 && r.PackageCoverageMax == nil {
+		return packages, packages
+	}
+	listed = make(reportPackageList, 0, len(packages))
+	for _, rp := range packages {
+		cov := rp.PercentageReached()
+		if cov < r.PackageCoverageMin || r.PackageCoverageMax != nil && cov > *r.PackageCoverageMax {
+			continue
+		}
+		listed = append(listed, rp)
+	}
+	if r.OverviewFiltered {
+		return listed, listed
+	}
+	return listed, packages
+}
+
+// buildReportPackages returns the report data of all the packages of the report.
+func buildReportPackages(r *report) reportPackageList {
+	rps := make(reportPackageList, len(r.packages))
+	for i, pkg := range r.packages {
+		rps[i] = buildReportPackage(pkg, r)
+	}
+	if r.InputOrder {
+		sort.SliceStable(rps, func(i, j int) bool {
+			return r.inputOrder[rps[i].Pkg.Name] < r.inputOrder[rps[j].Pkg.Name]
+		})
+	}
+	return rps
+}
+
+// unfiltered returns a copy of the report which keeps all functions,
+// whatever their coverage.
+func (r *report) unfiltered() *report {
+	u := *r
+	u.CoverageMin, u.CoverageMax = 0, 100
+	return &u
+}
+
+// overview sums up the statements of all packages.
+func overview(packages reportPackageList) reportPackage {
+	rv := reportPackage{
+		Pkg: &gocov.Package{Name: "Report Total"},
+	}
+	for _, rp := range packages {
+		rv.ReachedStatements += rp.ReachedStatements
+		rv.TotalStatements += rp.TotalStatements
+		if rp.Exported != nil {
+			if rv.Exported == nil {
+				rv.Exported = &statementCount{}
+			}
+			rv.Exported.TotalStatements += rp.Exported.TotalStatements
+			rv.Exported.ReachedStatements += rp.Exported.ReachedStatements
+		}
 	}
 	return rv
 }
 
 // printReport prints a coverage report to the given writer.
 func printReport(w io.Writer, r *report) error {
-	data := curTheme.Data()
+	theme := Current()
+	data := theme.Data()
 
 	// Base64 decoding of style data and script.
 	s, err := base64.StdEncoding.DecodeString(data.Style)
@@ -134,32 +757,78 @@ func printReport(w io.Writer, r *report) error {
 		}
 		css = string(style)
 	}
-	reportPackages := make(reportPackageList, len(r.packages))
+	reportPackages, totals := r.listedPackages(buildReportPackages(r))
 	pkgNames := make([]string, len(r.packages))
 	for i, pkg := range r.packages {
-		reportPackages[i] = buildReportPackage(pkg, r)
 		pkgNames[i] = pkg.Name
 	}
 
+	if r.LastModified {
+		for i := range reportPackages {
+			reportPackages[i].Modified = lastModified(&reportPackages[i])
+		}
+	}
+
+	var history coverageHistory
+	if r.History != "" {
+		history = loadHistory(r.History, r.HistoryLength)
+		for i := range reportPackages {
+			rp := &reportPackages[i]
+			rp.Trend = history.trend(rp.Pkg.Name, rp.PercentageReached())
+		}
+	}
+
+	data.DataVersion = DataVersion
 	data.Script = string(sc)
 	data.Style = css
 	data.Packages = reportPackages
+	data.HideSource = r.HideSource
+	data.SkipEmptyFunctions = r.SkipEmptyFunctions
+	data.BaseHref = r.BaseHref
+	data.Gate = r.gate
+	data.CopyCommand = r.CopyCommand
+	data.Tabs = r.Tabs
+	data.LastModified = r.LastModified
+	data.Minimap = r.Minimap && !r.HideSource && !r.LazySource
+	data.LazySource = r.LazySource && !r.HideSource
+	data.LazySourceNoScript = r.LazySourceNoScript
+	data.Trend = len(history) > 0
+	data.ThousandsSeparator = r.ThousandsSeparator
+	data.HeadHTML = r.HeadHTML
 	data.Command = fmt.Sprintf("gocov test %s | gocov-html %s",
 		strings.Join(pkgNames, " "),
-		strings.Join(os.Args[1:], " "),
+		strings.Join(r.redactArgs(os.Args[1:]), " "),
 	)
 
-	if len(reportPackages) > 1 {
-		rv := reportPackage{
-			Pkg: &gocov.Package{Name: "Report Total"},
-		}
-		for _, rp := range reportPackages {
-			rv.ReachedStatements += rp.ReachedStatements
-			rv.TotalStatements += rp.TotalStatements
-		}
+	if len(totals) > 1 {
+		rv := overview(totals)
+		rv.Trend = history.trend("", rv.PercentageReached())
 		data.Overview = &rv
 	}
-	err = curTheme.Template().Execute(w, data)
+	if r.Gauge && len(totals) > 0 {
+		total := overview(totals)
+		data.Gauge = gaugeSVG(total.PercentageReached())
+	}
+	if r.Preview {
+		data.Preview = newPreview(totals, r.BaseHref, r.PreviewImage, r.ThousandsSeparator)
+	}
+	if r.Focus != "" {
+		data.Focus = focusedFunctions(reportPackages, r.LowCoverageOnTop)
+	}
+	if r.LargeFunctions > 0 && r.LargeFunctionsOutput != largeFunctionsStderr {
+		data.LargeFunctions = largeFunctions(buildReportPackages(r.unfiltered()), r.LargeFunctions)
+		data.LargeFunctionsThreshold = r.LargeFunctions
+	}
+	if r.Extensions {
+		data.Extensions = extensionBreakdown(totals)
+	}
+	if r.owners != nil {
+		data.Owners = ownerBreakdown(totals)
+	}
+	if r.renderer != nil {
+		return r.renderer.execute(w, theme, data, r.ReportOptions)
+	}
+	err = theme.Template().Execute(w, data)
 	return eris.Wrap(err, "execute template")
 }
 
@@ -173,9 +842,48 @@ func exists(path string) (bool, error) {
 // HTMLReportCoverage outputs an HTML report on stdout by
 // parsing JSON data generated by axw/gocov. The css parameter
 // is an absolute path to a custom stylesheet. Use an empty
-// string to use the default stylesheet available.
+// string to use the default stylesheet available. Other output
+// formats can be selected with opts.Format.
+//
+// Once the report is written, the coverage gate is evaluated if any of its
+// thresholds is set. An error wrapping ErrGateFailed is returned if the gate
+// fails.
 func HTMLReportCoverage(r io.Reader, opts ReportOptions) error {
-	t0 := time.Now()
+	return WriteReportCoverage(os.Stdout, r, opts)
+}
+
+// ErrWrite is returned when the report can't be written to its output, like
+// a closed pipe. The output then holds a truncated report.
+var ErrWrite = errors.New("write report")
+
+// errWriter records the first error of the writer it wraps, so that output
+// errors can be told apart from rendering errors.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) Write(p []byte) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+	n, err := ew.w.Write(p)
+	if err != nil {
+		ew.err = err
+	}
+	return n, err
+}
+
+// WriteReportCoverage is like HTMLReportCoverage but writes the report to w.
+// An error wrapping ErrWrite is returned if w fails, in which case only part
+// of the report has been written.
+func WriteReportCoverage(w io.Writer, r io.Reader, opts ReportOptions) error {
+	return writeReportCoverage(w, r, opts, nil)
+}
+
+// loadReport checks the report options and reads the coverage data of a
+// report, and of its baseline if any.
+func loadReport(r io.Reader, opts ReportOptions) (*report, error) {
 	report := newReport()
 	report.ReportOptions = opts
 
@@ -183,29 +891,180 @@ func HTMLReportCoverage(r io.Reader, opts ReportOptions) error {
 	stylesheet := ""
 	if opts.Stylesheet != "" {
 		if _, err := exists(opts.Stylesheet); err != nil {
-			return eris.Wrap(err, "stylesheet")
+			return nil, eris.Wrap(err, "stylesheet")
 		}
 		stylesheet = opts.Stylesheet
 	}
 	report.Stylesheet = stylesheet
 
+	if report.Format == "" {
+		report.Format = FormatHTML
+	}
+	if !validFormat(report.Format) {
+		return nil, eris.Errorf("unknown format %q", report.Format)
+	}
+
+	href, err := normalizeBaseHref(opts.BaseHref)
+	if err != nil {
+		return nil, err
+	}
+	report.BaseHref = href
+	if _, err := parseSymbols(opts.Symbols); err != nil {
+		return nil, err
+	}
+	if !validLargeFunctionsOutput(opts.LargeFunctionsOutput) {
+		return nil, eris.Errorf("unknown large functions output %q", opts.LargeFunctionsOutput)
+	}
+	image, err := previewImageURL(opts.PreviewImage, href)
+	if err != nil {
+		return nil, err
+	}
+	report.PreviewImage = image
+
 	data, err := ioutil.ReadAll(r)
 	if err != nil {
-		return eris.Wrap(err, "read coverage data")
+		return nil, eris.Wrap(err, "read coverage data")
 	}
 
 	packages, err := unmarshalJSON(data)
 	if err != nil {
-		return eris.Wrap(err, "unmarshal coverage data")
+		return nil, eris.Wrap(err, "unmarshal coverage data")
+	}
+	if opts.File != "" {
+		if packages, err = filterFile(packages, opts.File); err != nil {
+			return nil, err
+		}
+		if len(packages) == 0 {
+			return nil, eris.Errorf("no function found in file %q", opts.File)
+		}
+	}
+	var discard *regexp.Regexp
+	if opts.DiscardReached != "" {
+		if discard, err = regexp.Compile(opts.DiscardReached); err != nil {
+			return nil, eris.Wrap(err, "discarded files")
+		}
+		discardReached(packages, discard)
+	}
+	if opts.NotApplicable != "" {
+		if report.naFiles, err = regexp.Compile(opts.NotApplicable); err != nil {
+			return nil, eris.Wrap(err, "not applicable files")
+		}
+	}
+	if opts.CodeOwners != "" {
+		if report.owners, err = loadCodeOwners(opts.CodeOwners, opts.PathBase); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Helpers != "" {
+		if report.helpers, err = regexp.Compile(opts.Helpers); err != nil {
+			return nil, eris.Wrap(err, "helpers")
+		}
+	}
+	var focus *regexp.Regexp
+	if opts.Focus != "" {
+		if focus, err = regexp.Compile(opts.Focus); err != nil {
+			return nil, eris.Wrap(err, "focus")
+		}
+		packages = filterFocus(packages, focus)
+		if len(packages) == 0 {
+			return nil, eris.Errorf("no function matches %q", opts.Focus)
+		}
 	}
 
 	for _, pkg := range packages {
-		report.addPackage(pkg)
+		if err := report.addPackage(pkg); err != nil {
+			return nil, eris.Wrap(err, "merge coverage data")
+		}
+	}
+	if opts.Baseline != "" && opts.BaselineGit != "" {
+		return nil, eris.New("baseline: use either a file or a git object")
+	}
+	if opts.GateNewFunctions > 0 && opts.Baseline == "" && opts.BaselineGit == "" {
+		return nil, eris.New("functions gate: a baseline is required")
+	}
+	if opts.GateStatements && opts.Baseline == "" && opts.BaselineGit == "" {
+		return nil, eris.New("statements gate: a baseline is required")
+	}
+	if opts.Baseline != "" {
+		if report.baseline, err = loadCoverageFile(opts.Baseline); err != nil {
+			return nil, eris.Wrap(err, "baseline")
+		}
+	}
+	if opts.BaselineGit != "" {
+		if report.baseline, err = loadCoverageGit(opts.BaselineGit); err != nil {
+			return nil, eris.Wrap(err, "baseline")
+		}
+	}
+	if report.baseline != nil {
+		if opts.File != "" {
+			if report.baseline, err = filterFile(report.baseline, opts.File); err != nil {
+				return nil, eris.Wrap(err, "baseline")
+			}
+		}
+		if discard != nil {
+			discardReached(report.baseline, discard)
+		}
+		if focus != nil {
+			report.baseline = filterFocus(report.baseline, focus)
+		}
+	}
+	return report, nil
+}
+
+// writeReportCoverage writes the report to w, reusing the package sections
+// previously rendered by rd if not nil.
+func writeReportCoverage(w io.Writer, r io.Reader, opts ReportOptions, rd *Renderer) error {
+	t0 := time.Now()
+	report, err := loadReport(r, opts)
+	if err != nil {
+		return err
+	}
+	report.renderer = rd
+	return writeLoadedReport(w, report, t0)
+}
+
+// writeLoadedReport writes a report, loaded since t0, to w. The coverage gate
+// is evaluated, and its result is kept in the report.
+func writeLoadedReport(w io.Writer, report *report, t0 time.Time) error {
+	opts := report.ReportOptions
+	quiet := report.renderer != nil && report.renderer.quiet
+	if !quiet && report.LargeFunctions > 0 && (report.LargeFunctionsOutput == largeFunctionsStderr || report.LargeFunctionsOutput == largeFunctionsBoth) {
+		fns := largeFunctions(buildReportPackages(report.unfiltered()), report.LargeFunctions)
+		if err := warnLargeFunctions(os.Stderr, fns, report.LargeFunctions); err != nil {
+			return err
+		}
+	}
+	gate := evalGate(report, buildReportPackages(report))
+	report.gate = gate
+	ew := &errWriter{w: w}
+	var out io.Writer = ew
+	var zw *gzip.Writer
+	if opts.Gzip {
+		zw = gzip.NewWriter(ew)
+		out = zw
+	}
+	err := writeReport(out, report)
+	if zw != nil {
+		if cerr := zw.Close(); err == nil {
+			err = eris.Wrap(cerr, "gzip")
+		}
+	}
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "Took %v\n", time.Since(t0))
+	}
+	if ew.err != nil {
+		return eris.Wrapf(ErrWrite, "%s report: %v", report.Format, ew.err)
 	}
-	fmt.Println()
-	err = printReport(os.Stdout, report)
-	fmt.Fprintf(os.Stderr, "Took %v\n", time.Since(t0))
-	return eris.Wrap(err, "HTML report")
+	if err != nil {
+		return eris.Wrapf(err, "%s report", report.Format)
+	}
+
+	if gate != nil && opts.GateResult != "" {
+		if err := gate.writeFile(opts.GateResult); err != nil {
+			return err
+		}
+	}
+	return gate.Err()
 }
 
 // ProjectURL is the project's site on GitHub.
@@ -219,10 +1078,74 @@ const (
 type reportPackageList []reportPackage
 
 type reportPackage struct {
-	Pkg               *gocov.Package
-	Functions         reportFunctionList
-	TotalStatements   int
+	// Pkg is the package as found in the coverage data.
+	Pkg *gocov.Package
+	// Functions is the list of functions of the package, in report order.
+	Functions reportFunctionList
+	// TotalStatements is the number of statements of the package.
+	TotalStatements int
+	// ReachedStatements is the number of statements of the package covered by
+	// the tests.
 	ReachedStatements int
+	// TotalFunctions is the number of functions of the package.
+	TotalFunctions int
+	// CoveredFunctions is the number of functions whose statements are all covered.
+	CoveredFunctions int
+	// functionCoverageSum is the sum of the coverage percentages of all counted functions.
+	functionCoverageSum float64
+	// extensions holds the statement counts per file extension.
+	extensions map[string]*extensionCoverage
+	// owners holds the statement counts per owner of the files. Is nil unless
+	// a CODEOWNERS file is set.
+	owners map[string]*ownerCoverage
+	// Helpers holds the functions matching the helpers option, with their own
+	// totals, left out of the package ones. Is nil if no function matches.
+	Helpers *reportPackage
+	// Exported counts the statements of the exported functions of the package.
+	// Is nil unless requested.
+	Exported *statementCount
+	// Modified is the most recent modification time of the source files of the
+	// package. Is zero unless requested, or if no source file can be read.
+	Modified time.Time
+	// Groups holds the functions of the package grouped by receiver type,
+	// package-level functions first. Functions is sorted in the same order. Is
+	// nil unless requested.
+	Groups []*functionGroup
+	// Trend is an inline SVG sparkline of the coverage of the package over the
+	// recorded runs, ending with the current one. Is empty unless requested,
+	// or if the package has no recorded coverage.
+	Trend string
+}
+
+// FunctionGroups returns the groups of functions of the package by receiver
+// type if requested, or a single group of all its functions otherwise, so
+// that themes render both cases alike.
+func (rp *reportPackage) FunctionGroups() []*functionGroup {
+	if rp.Groups != nil {
+		return rp.Groups
+	}
+	g := &functionGroup{Functions: rp.Functions}
+	g.TotalStatements, g.ReachedStatements = rp.TotalStatements, rp.ReachedStatements
+	return []*functionGroup{g}
+}
+
+// CommentName returns the name of the package for use in an HTML comment, like
+// the markers parsed by external scripts. Comments are not HTML, so the name is
+// not escaped, but "--" is removed so that it can't close the comment.
+func (rp *reportPackage) CommentName() string {
+	name := rp.Pkg.Name
+	for strings.Contains(name, "--") {
+		name = strings.Replace(name, "--", "", -1)
+	}
+	return name
+}
+
+// helperFunctions returns the functions of the helpers section, if any.
+func (rp *reportPackage) helperFunctions() reportFunctionList {
+	if rp.Helpers == nil {
+		return nil
+	}
+	return rp.Helpers.Functions
 }
 
 // PercentageReached computes the percentage of reached statements by the tests
@@ -235,18 +1158,88 @@ func (rp *reportPackage) PercentageReached() float64 {
 	return rv
 }
 
+// covered reports whether a statement has been reached at least minHits times.
+func covered(stmt *gocov.Statement, minHits int64) bool {
+	if minHits < 1 {
+		minHits = 1
+	}
+	return stmt.Reached >= minHits
+}
+
+// AverageFunctionCoverage is the mean of the coverage percentages of the
+// package's functions.
+func (rp *reportPackage) AverageFunctionCoverage() float64 {
+	if rp.TotalFunctions == 0 {
+		return 0
+	}
+	return rp.functionCoverageSum / float64(rp.TotalFunctions)
+}
+
 // reportFunction is a gocov Function with some added stats.
 type reportFunction struct {
+	// Function is the function as found in the coverage data.
 	*gocov.Function
+	// StatementsReached is the number of statements of the function covered by
+	// the tests.
 	StatementsReached int
+	// DisplayFile is the path to the function's file as shown in the report.
+	DisplayFile string
+	// Signature is the name of the function followed by its parameters and results,
+	// if available.
+	Signature string
+	// ID identifies the function within its package. It is its name, followed by
+	// a number if several functions have the same name, like init functions.
+	ID string
+	// Anchor identifies the function within the report, for the ids of its
+	// elements in the page: its package name and its ID, separated by a colon,
+	// which can't be part of a package name.
+	Anchor string
+	// NotApplicable is true if the function can't be covered and is left out of
+	// the totals.
+	NotApplicable bool
+	// minHits is the number of hits required for a statement to be covered.
+	minHits int64
 }
 
 // functionLine holds the line of code, its line number in the source file
-// and whether the tests reached it.
+// and whether the tests reached it. Lines without statement are neither
+// missed nor covered.
 type functionLine struct {
-	Code       string
+	// Code is the source of the line, HTML escaped, with tabs expanded to
+	// spaces.
+	Code string
+	// LineNumber is the 1-based number of the line in the source file.
 	LineNumber int
-	Missed     bool
+	// Missed is true if the line holds a statement the tests didn't reach.
+	Missed bool
+	// Covered is true if the line holds statements, all reached by the tests.
+	Covered bool
+}
+
+// statementHit holds the line number of a statement and the number of times
+// the tests reached it.
+type statementHit struct {
+	// LineNumber is the line of the start of the statement.
+	LineNumber int
+	// Reached is the number of times the tests reached the statement.
+	Reached int64
+	// Covered is true if Reached is enough for the statement to be covered.
+	Covered bool
+}
+
+// StatementHits returns the hit count of every statement of the function, in
+// source order. Line numbers are 0 if the source file can't be read.
+func (f reportFunction) StatementHits() []statementHit {
+	src, _ := loadSource(f.File)
+	hits := make([]statementHit, len(f.Statements))
+	for i, st := range f.Statements {
+		hits[i].Reached = st.Reached
+		hits[i].Covered = covered(st, f.minHits)
+		if src != nil {
+			hits[i].LineNumber = src.Line(st.Start)
+		}
+	}
+	return hits
 }
 
 // CoveragePercent is the percentage of code coverage for a function. Returns 100
@@ -262,6 +1255,30 @@ func (f reportFunction) CoveragePercent() float64 {
 	return stmtPercent
 }
 
+// Label is the name of the function as displayed in the report. Function
+// literals and init functions, which may not have a unique name, are labeled
+// with their location.
+func (f reportFunction) Label() string {
+	switch {
+	case f.IsLiteral():
+		// Function literals are named after their position, like "@15:9".
+		return fmt.Sprintf("func literal at %s:%s", f.ShortFileName(), f.Name[1:])
+	case f.Name == "init":
+		if src, err := loadSource(f.File); err == nil {
+			return fmt.Sprintf("init() at %s:%d", f.ShortFileName(), src.Line(f.Start))
+		}
+		return fmt.Sprintf("init() in %s", f.ShortFileName())
+	case f.Signature != "":
+		return f.Signature
+	}
+	return f.Name + "(...)"
+}
+
+// IsLiteral reports whether the function is a function literal.
+func (f reportFunction) IsLiteral() bool {
+	return strings.HasPrefix(f.Name, "@")
+}
+
 // ShortFileName returns the base path of the function's file name. Provided for
 // convenience to be used in the HTML template of the theme.
 func (f reportFunction) ShortFileName() string {
@@ -270,40 +1287,14 @@ func (f reportFunction) ShortFileName() string {
 
 // Lines returns information about all a function's Lines of code.
 func (f reportFunction) Lines() []functionLine {
-	type annotator struct {
-		fset  *token.FileSet
-		files map[string]*token.File
-	}
-	a := &annotator{}
-	a.fset = token.NewFileSet()
-	a.files = make(map[string]*token.File)
-
-	// Load the file for line information. Probably overkill, maybe
-	// just compute the lines from offsets in here.
-	setContent := false
-	file := a.files[f.File]
-	if file == nil {
-		info, err := os.Stat(f.File)
-		if err != nil {
-			panic(err)
-		}
-		file = a.fset.AddFile(f.File, a.fset.Base(), int(info.Size()))
-		setContent = true
-	}
-
-	data, err := ioutil.ReadFile(f.File)
+	src, err := loadSource(f.File)
 	if err != nil {
 		panic(err)
 	}
 
-	if setContent {
-		// This processes the content and records line number info.
-		file.SetLinesForContent(data)
-	}
-
 	statements := f.Statements[:]
-	lineno := file.Line(file.Pos(f.Start))
-	lines := strings.Split(string(data)[f.Start:f.End], "\n")
+	lineno := src.Line(f.Start)
+	lines := strings.Split(string(src.data[f.Start:f.End]), "\n")
 	fls := make([]functionLine, len(lines))
 
 	for i, line := range lines {
@@ -311,10 +1302,10 @@ func (f reportFunction) Lines() []functionLine {
 		statementFound := false
 		hit := false
 		for j := 0; j < len(statements); j++ {
-			start := file.Line(file.Pos(statements[j].Start))
+			start := src.Line(statements[j].Start)
 			if start == lineno {
 				statementFound = true
-				if !hit && statements[j].Reached > 0 {
+				if !hit && covered(statements[j], f.minHits) {
 					hit = true
 				}
 				statements = append(statements[:j], statements[j+1:]...)
@@ -326,6 +1317,7 @@ func (f reportFunction) Lines() []functionLine {
 		}
 		fls[i] = functionLine{
 			Missed:     hitmiss == missPrefix,
+			Covered:    statementFound && hit,
 			LineNumber: lineno,
 			Code:       html.EscapeString(strings.Replace(line, "\t", "        ", -1)),
 		}
@@ -333,6 +1325,44 @@ func (f reportFunction) Lines() []functionLine {
 	return fls
 }
 
+// focusedFunction is a function along with its package.
+type focusedFunction struct {
+	reportFunction
+	// Pkg is the package of the function.
+	Pkg *gocov.Package
+}
+
+// focusedFunctionList is a list of functions of several packages.
+type focusedFunctionList []focusedFunction
+
+func (l focusedFunctionList) Len() int {
+	return len(l)
+}
+
+func (l focusedFunctionList) Less(i, j int) bool {
+	return reportFunctionList{l[i].reportFunction, l[j].reportFunction}.Less(0, 1)
+}
+
+func (l focusedFunctionList) Swap(i, j int) {
+	l[i], l[j] = l[j], l[i]
+}
+
+// focusedFunctions returns the functions of all packages, sorted by coverage.
+func focusedFunctions(packages reportPackageList, lowCoverageOnTop bool) focusedFunctionList {
+	fns := make(focusedFunctionList, 0)
+	for _, rp := range packages {
+		for _, f := range rp.Functions {
+			fns = append(fns, focusedFunction{reportFunction: f, Pkg: rp.Pkg})
+		}
+	}
+	if lowCoverageOnTop {
+		sort.Stable(fns)
+	} else {
+		sort.Stable(reverse{fns})
+	}
+	return fns
+}
+
 // reportFunctionList is a list of functions for a report.
 type reportFunctionList []reportFunction
 
@@ -0,0 +1,161 @@
+package themes
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rotisserie/eris"
+)
+
+// contentTypes holds the Content-Type of the reports served, per format.
+var contentTypes = map[string]string{
+	FormatHTML:      "text/html; charset=utf-8",
+	FormatNDJSON:    "application/x-ndjson",
+	FormatEditorMap: "application/json",
+	FormatDigest:    "text/plain; charset=utf-8",
+	FormatSonar:     "application/xml",
+	// Version of the Prometheus text exposition format.
+	FormatPrometheus: "text/plain; version=0.0.4; charset=utf-8",
+	FormatText:       "text/plain; charset=utf-8",
+	FormatMarkdown:   "text/markdown; charset=utf-8",
+}
+
+// acceptsGzip returns true if the client advertises gzip support in its
+// Accept-Encoding header.
+func acceptsGzip(req *http.Request) bool {
+	for _, enc := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		parts := strings.Split(enc, ";")
+		if strings.TrimSpace(parts[0]) != "gzip" {
+			continue
+		}
+		for _, p := range parts[1:] {
+			p = strings.TrimSpace(p)
+			if !strings.HasPrefix(p, "q=") {
+				continue
+			}
+			// gzip is explicitly refused with a zero quality value.
+			if q, err := strconv.ParseFloat(p[len("q="):], 64); err == nil && q == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// serveBufferSize is the size up to which served reports are rendered in
+// memory before being sent.
+const serveBufferSize = 4 << 20
+
+// bufferedResponse keeps the first serveBufferSize bytes of a response in
+// memory, then streams the rest.
+type bufferedResponse struct {
+	w         http.ResponseWriter
+	buf       bytes.Buffer
+	streaming bool
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if !b.streaming {
+		if b.buf.Len()+len(p) <= serveBufferSize {
+			return b.buf.Write(p)
+		}
+		if err := b.stream(); err != nil {
+			return 0, err
+		}
+	}
+	return b.w.Write(p)
+}
+
+// stream sends the buffered data and switches to streaming.
+func (b *bufferedResponse) stream() error {
+	b.streaming = true
+	_, err := b.buf.WriteTo(b.w)
+	return err
+}
+
+// flush sends the complete response, with its length if it was buffered.
+func (b *bufferedResponse) flush() error {
+	if !b.streaming {
+		b.w.Header().Set("Content-Length", strconv.Itoa(b.buf.Len()))
+	}
+	return b.stream()
+}
+
+// maxRequestBody is the largest request body accepted by a Handler. Reports
+// don't depend on the body of requests, which is never read.
+const maxRequestBody = 1 << 10
+
+// Handler returns an HTTP handler rendering a report of the coverage data
+// for every request. The report is streamed gzip-compressed to clients
+// supporting it, whatever opts.Gzip. A failed coverage gate is not an error
+// and the report is served anyway. Requests neither write opts.GateResult nor
+// any message to stderr, like the rendering time: render the report once
+// before serving for those, and to check the options. The coverage data never
+// changes: package sections are rendered once, then reused by all requests,
+// compressed or not, until their source files change. Source files are read
+// again once their size or modification time changed.
+//
+// Reports are rendered in memory before being sent, so that a rendering error
+// results in a 500 response rather than a truncated report. Reports larger
+// than 4 MiB are streamed: if rendering fails once part of them has been sent,
+// the connection is aborted so that clients don't take them for complete.
+// Requests with a body larger than 1 KiB are rejected.
+func Handler(data []byte, opts ReportOptions) http.Handler {
+	rd := NewRenderer()
+	rd.quiet = true
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.ContentLength > maxRequestBody {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+		req.Body = http.MaxBytesReader(w, req.Body, maxRequestBody)
+		o := opts
+		o.Gzip = acceptsGzip(req)
+		o.GateResult = ""
+		format := o.Format
+		if format == "" {
+			format = FormatHTML
+		}
+		w.Header().Set("Content-Type", contentTypes[format])
+		w.Header().Add("Vary", "Accept-Encoding")
+		if o.Gzip {
+			w.Header().Set("Content-Encoding", "gzip")
+		}
+		resp := &bufferedResponse{w: w}
+		err := rd.WriteReportCoverage(resp, bytes.NewReader(data), o)
+		if err != nil && !eris.Is(err, ErrGateFailed) {
+			log.Printf("serve %s: %v", req.URL.Path, err)
+			if resp.streaming {
+				panic(http.ErrAbortHandler)
+			}
+			w.Header().Del("Content-Encoding")
+			http.Error(w, "failed to render the report", http.StatusInternalServerError)
+			return
+		}
+		// Errors here mean the client went away.
+		resp.flush()
+	})
+}
+
+// LimitConcurrency returns a handler serving at most n requests with h at a
+// time, since rendering large reports is expensive. Other requests wait for
+// their turn, until their client goes away. There is no limit if n < 1.
+func LimitConcurrency(h http.Handler, n int) http.Handler {
+	if n < 1 {
+		return h
+	}
+	sem := make(chan struct{}, n)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+			h.ServeHTTP(w, req)
+		case <-req.Context().Done():
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		}
+	})
+}
@@ -0,0 +1,269 @@
+package themes
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{"none", "", false},
+		{"gzip", "gzip", true},
+		{"list", "deflate, gzip;q=0.8, br", true},
+		{"refused", "gzip;q=0, deflate", false},
+		{"refused with decimals", "gzip; q=0.000", false},
+		{"other", "deflate, br", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Encoding", tt.header)
+			if got := acceptsGzip(req); got != tt.want {
+				t.Errorf("acceptsGzip() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	data := []byte(`{"Packages":[{"Name":"p","Functions":[{"Name":"f","Statements":[{"Reached":1},{"Reached":0}]}]}]}`)
+	h := Handler(data, ReportOptions{Format: FormatDigest, CoverageMax: 100, MinHits: 1})
+	tests := []struct {
+		name     string
+		encoding string
+		gzipped  bool
+	}{
+		{"plain", "", false},
+		{"gzip", "gzip, deflate", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Encoding", tt.encoding)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if got := rec.Header().Get("Content-Type"); got != "text/plain; charset=utf-8" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			body := rec.Body.Bytes()
+			if enc := rec.Header().Get("Content-Encoding"); (enc == "gzip") != tt.gzipped {
+				t.Fatalf("Content-Encoding = %q", enc)
+			}
+			if tt.gzipped {
+				zr, err := gzip.NewReader(rec.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if body, err = ioutil.ReadAll(zr); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if want := " 50.0%  p\n"; string(body) != want {
+				t.Errorf("body = %q, want %q", body, want)
+			}
+		})
+	}
+}
+
+// TestHandlerSourceChanged checks that a source file edited while serving is
+// shown as it is now, not as it was read by the first request.
+func TestHandlerSourceChanged(t *testing.T) {
+	src := "package p\n\nfunc f() {\n\told()\n}\n"
+	dir, path := writeSource(t, src)
+	defer os.RemoveAll(dir)
+	data := []byte(fmt.Sprintf(`{"Packages":[{"Name":"p","Functions":[{"Name":"f","File":%q,"Start":%d,"End":%d,"Statements":[{"Start":%d,"Reached":1}]}]}]}`,
+		path, strings.Index(src, "func"), len(src)-1, strings.Index(src, "old")))
+	h := Handler(data, ReportOptions{CoverageMax: 100, MinHits: 1})
+	get := func() string {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec.Body.String()
+	}
+	if body := get(); !strings.Contains(body, "old()") {
+		t.Fatal("source missing from the report")
+	}
+	if err := ioutil.WriteFile(path, []byte(strings.Replace(src, "old", "new", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Make sure the modification time changes, whatever its resolution.
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if body := get(); !strings.Contains(body, "new()") || strings.Contains(body, "old()") {
+		t.Error("got the previous source after the file changed")
+	}
+}
+
+// TestHandlerQuiet checks that requests don't write the gate result nor any
+// message to stderr, which are left to the render checking the options.
+func TestHandlerQuiet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gocov-html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	stderr, err := os.Create(filepath.Join(dir, "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(old *os.File) { os.Stderr = old }(os.Stderr)
+	os.Stderr = stderr
+
+	data := []byte(`{"Packages":[{"Name":"p","Functions":[{"Name":"f","Statements":[{"Reached":1},{"Reached":0}]}]}]}`)
+	result := filepath.Join(dir, "gate.json")
+	h := Handler(data, ReportOptions{
+		Format: FormatDigest, CoverageMax: 100, MinHits: 1,
+		GateTotal: 90, GateResult: result,
+		LargeFunctions: 1, LargeFunctionsOutput: largeFunctionsStderr,
+	})
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d", rec.Code)
+		}
+	}
+	if _, err := os.Stat(result); !os.IsNotExist(err) {
+		t.Error("requests wrote the gate result")
+	}
+	if out, _ := ioutil.ReadFile(stderr.Name()); len(out) > 0 {
+		t.Errorf("requests wrote to stderr: %q", out)
+	}
+}
+
+func TestHandlerRequestBody(t *testing.T) {
+	data := []byte(`{"Packages":[]}`)
+	h := Handler(data, ReportOptions{Format: FormatDigest})
+	tests := []struct {
+		name string
+		size int
+		want int
+	}{
+		{"no body", 0, http.StatusOK},
+		{"small body", 10, http.StatusOK},
+		{"large body", maxRequestBody + 1, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(make([]byte, tt.size)))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("got status %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlerError(t *testing.T) {
+	h := Handler([]byte("not json"), ReportOptions{Format: FormatDigest})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if enc := rec.Header().Get("Content-Encoding"); enc != "" {
+		t.Errorf("Content-Encoding = %q, want none", enc)
+	}
+}
+
+func TestBufferedResponse(t *testing.T) {
+	rec := httptest.NewRecorder()
+	resp := &bufferedResponse{w: rec}
+	small := bytes.Repeat([]byte("a"), serveBufferSize/2)
+	resp.Write(small)
+	if resp.streaming || rec.Body.Len() != 0 {
+		t.Fatal("small writes must be buffered")
+	}
+	resp.Write(small)
+	resp.Write([]byte("b"))
+	if !resp.streaming || rec.Body.Len() != serveBufferSize+1 {
+		t.Fatalf("got %d bytes sent, want all of them once the buffer is full", rec.Body.Len())
+	}
+	if cl := rec.Header().Get("Content-Length"); cl != "" {
+		t.Errorf("Content-Length = %q for a streamed response", cl)
+	}
+
+	rec = httptest.NewRecorder()
+	resp = &bufferedResponse{w: rec}
+	resp.Write([]byte("report"))
+	resp.flush()
+	if rec.Body.String() != "report" || rec.Header().Get("Content-Length") != "6" {
+		t.Errorf("got %q with Content-Length %q", rec.Body, rec.Header().Get("Content-Length"))
+	}
+}
+
+func TestContentTypes(t *testing.T) {
+	for _, f := range Formats {
+		if contentTypes[f] == "" {
+			t.Errorf("missing Content-Type for format %q", f)
+		}
+	}
+}
+
+func TestLimitConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	release := make(chan struct{})
+	h := LimitConcurrency(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		<-release
+		mu.Lock()
+		running--
+		mu.Unlock()
+	}), 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		}()
+	}
+	// Requests waiting for their turn give up when their client goes away.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for {
+		mu.Lock()
+		n := running
+		mu.Unlock()
+		if n == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("canceled request status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	close(release)
+	wg.Wait()
+	if maxRunning != 2 {
+		t.Errorf("got %d concurrent requests, want 2", maxRunning)
+	}
+}
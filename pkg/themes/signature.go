@@ -0,0 +1,62 @@
+package themes
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"strings"
+
+	"github.com/rotisserie/eris"
+)
+
+// fileSignatures parses a Go source file and returns the signatures of all its
+// function declarations, indexed by their start offset. A signature has the
+// same form as a gocov function name followed by the parameters and results,
+// like "T.Name(a int) error".
+func fileSignatures(path string) (map[int]string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return nil, eris.Wrap(err, "parse source file")
+	}
+	sigs := make(map[int]string)
+	for _, decl := range f.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		name := fd.Name.Name
+		if fd.Recv != nil && len(fd.Recv.List) > 0 {
+			if recv := receiverType(fd.Recv.List[0].Type); recv != "" {
+				name = recv + "." + name
+			}
+		}
+		var buf bytes.Buffer
+		if err := printer.Fprint(&buf, fset, fd.Type); err != nil {
+			return nil, eris.Wrap(err, "print signature")
+		}
+		// Parameters may span several lines.
+		typ := strings.Join(strings.Fields(buf.String()), " ")
+		typ = strings.NewReplacer("( ", "(", ", )", ")").Replace(typ)
+		sigs[fset.Position(fd.Pos()).Offset] = name + strings.TrimPrefix(typ, "func")
+	}
+	return sigs, nil
+}
+
+// receiverType returns the name of the type of a method receiver, without any
+// pointer or type parameters.
+func receiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverType(t.X)
+	case *ast.ParenExpr:
+		return receiverType(t.X)
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
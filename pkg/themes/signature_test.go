@@ -0,0 +1,52 @@
+package themes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileSignatures(t *testing.T) {
+	src := `package p
+
+type T struct{}
+
+func (t *T) Method(a, b int) (string, error) { return "", nil }
+
+func Free(
+	ch <-chan int,
+) {
+	_ = func() {}
+}
+`
+	dir, err := ioutil.TempDir("", "gocov-html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "p.go")
+	if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := fileSignatures(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]string{
+		strings.Index(src, "func (t"):   "T.Method(a, b int) (string, error)",
+		strings.Index(src, "func Free"): "Free(ch <-chan int)",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d signatures, want %d: %v", len(got), len(want), got)
+	}
+	for off, sig := range want {
+		if got[off] != sig {
+			t.Errorf("signature at %d = %q, want %q", off, got[off], sig)
+		}
+	}
+	if _, err := fileSignatures(filepath.Join(dir, "missing.go")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,112 @@
+package themes
+
+import (
+	"container/list"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rotisserie/eris"
+)
+
+// OffsetMapper converts a byte offset within a source file into a line number.
+type OffsetMapper interface {
+	// Line returns the 1-based line number of offset.
+	Line(offset int) int
+}
+
+// NewOffsetMapper returns the OffsetMapper of a source file, given its content.
+// It is used by all features needing line numbers (source listings, statement
+// hits, etc.) and can be replaced to support coverage data whose offsets are
+// not byte offsets.
+var NewOffsetMapper = func(data []byte) OffsetMapper {
+	return newLineIndex(data)
+}
+
+// lineIndex holds the offsets of the beginning of all lines of a file.
+type lineIndex []int
+
+func newLineIndex(data []byte) lineIndex {
+	idx := lineIndex{0}
+	for i, b := range data {
+		if b == '\n' {
+			idx = append(idx, i+1)
+		}
+	}
+	return idx
+}
+
+// Line returns the 1-based line number of a byte offset. Offsets out of range
+// are clamped to the first or last line.
+func (idx lineIndex) Line(offset int) int {
+	// Index of the first line starting after offset.
+	n := sort.SearchInts(idx, offset+1)
+	if n == 0 {
+		return 1
+	}
+	return n
+}
+
+// sourceFile is the content of a source file and its line index.
+type sourceFile struct {
+	data []byte
+	OffsetMapper
+	// path, size and modTime identify the version of the file that was read.
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// maxSourceCache is the total size of the source files kept in memory by
+// loadSource. The least recently used ones are evicted beyond it.
+var maxSourceCache int64 = 64 << 20
+
+var (
+	sourcesMu sync.Mutex
+	// sources caches the source files read last, indexing the elements of
+	// sourcesLRU, which orders them from the most recently used one.
+	sources    = make(map[string]*list.Element)
+	sourcesLRU = list.New()
+	// sourcesSize is the total size of the cached source files.
+	sourcesSize int64
+)
+
+// loadSource reads a source file, only once as long as its size and
+// modification time don't change, like the package sections of a Renderer,
+// and as long as it is not evicted from the cache by more recent files.
+func loadSource(path string) (*sourceFile, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, eris.Wrap(err, "read source file")
+	}
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	if e, ok := sources[path]; ok {
+		if src := e.Value.(*sourceFile); src.size == fi.Size() && src.modTime.Equal(fi.ModTime()) {
+			sourcesLRU.MoveToFront(e)
+			return src, nil
+		}
+		evictSource(e)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, eris.Wrap(err, "read source file")
+	}
+	src := &sourceFile{data: data, OffsetMapper: NewOffsetMapper(data), path: path, size: fi.Size(), modTime: fi.ModTime()}
+	sources[path] = sourcesLRU.PushFront(src)
+	sourcesSize += src.size
+	// The file just read is kept, even if larger than the cache.
+	for sourcesSize > maxSourceCache && sourcesLRU.Len() > 1 {
+		evictSource(sourcesLRU.Back())
+	}
+	return src, nil
+}
+
+// evictSource removes a source file from the cache. sourcesMu must be held.
+func evictSource(e *list.Element) {
+	src := sourcesLRU.Remove(e).(*sourceFile)
+	delete(sources, src.path)
+	sourcesSize -= src.size
+}
@@ -0,0 +1,85 @@
+package themes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLineIndex(t *testing.T) {
+	// Multi-byte UTF-8 characters must not shift line numbers since gocov
+	// offsets are byte offsets.
+	src := "package é\n\n// ünïcödé ✓\nfunc 日本() {}\n"
+	idx := newLineIndex([]byte(src))
+	tests := []struct {
+		name   string
+		offset int
+		want   int
+	}{
+		{"first byte", 0, 1},
+		{"multi-byte char", strings.Index(src, "é"), 1},
+		{"end of first line", strings.Index(src, "\n"), 1},
+		{"empty line", strings.Index(src, "\n") + 1, 2},
+		{"comment", strings.Index(src, "✓"), 3},
+		{"func", strings.Index(src, "func"), 4},
+		{"after multi-byte chars", strings.Index(src, "()"), 4},
+		{"end of file", len(src), 5},
+		{"out of range", len(src) + 10, 5},
+		{"negative", -1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := idx.Line(tt.offset); got != tt.want {
+				t.Errorf("Line(%d) = %d, want %d", tt.offset, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadSourceEviction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gocov-html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(old int64) { maxSourceCache = old }(maxSourceCache)
+	maxSourceCache = 20
+	var paths []string
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte("package p // 10"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	cached := func(path string) bool {
+		sourcesMu.Lock()
+		defer sourcesMu.Unlock()
+		_, ok := sources[path]
+		return ok
+	}
+	for _, path := range paths {
+		if _, err := loadSource(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Only the last file read fits in the cache.
+	if cached(paths[0]) || cached(paths[1]) || !cached(paths[2]) {
+		t.Errorf("cached files a: %v, b: %v, c: %v, want c only", cached(paths[0]), cached(paths[1]), cached(paths[2]))
+	}
+	sourcesMu.Lock()
+	size := sourcesSize
+	sourcesMu.Unlock()
+	if size > maxSourceCache {
+		t.Errorf("cache size = %d, want at most %d", size, maxSourceCache)
+	}
+	src, err := loadSource(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(src.data); got != "package p // 10" {
+		t.Errorf("evicted file read again as %q", got)
+	}
+}
@@ -1,6 +1,10 @@
 package themes
 
 import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sync"
 	"text/template"
 
 	"github.com/rotisserie/eris"
@@ -21,10 +25,31 @@ type Beautifier interface {
 	Data() *templateData
 }
 
+// DataVersion is the version of the data given to the templates of themes,
+// available to them as .DataVersion. The data only ever gets new fields, so a
+// template written against a version keeps rendering with later ones; the
+// version is bumped each time fields are added, letting a theme check for them
+// with {{if ge .DataVersion 2}}. Renaming or removing a field would be a
+// breaking change, only made with a new major version of gocov-html.
+//
+// Version 1 is the data documented by the fields of templateData, and of the
+// types they refer to. Version 2 adds Trend, and the Trend field of packages.
+// Version 3 adds LazySource and LazySourceNoScript, and the LinesJSON method
+// of functions. Version 4 adds the Groups field and the FunctionGroups method
+// of packages. Version 5 adds the Anchor field of functions.
+const DataVersion = 5
+
 // templateData has all the fields needed by the the HTML template for rendering.
+// See DataVersion for its compatibility guarantees: new fields are appended,
+// existing ones keep their name, type and meaning.
 type templateData struct {
+	// DataVersion is the value of the DataVersion constant the report was
+	// rendered with.
+	DataVersion int
 	// Command is the shell Command used to generate the HTML report.
 	Command string
+	// CopyCommand is true if a button copying the Command to the clipboard must be rendered.
+	CopyCommand bool
 	// Style is the stylesheet content that will be embedded in the HTML page.
 	Style string
 	// Script is the javascript content that will be embedded in the HTML page.
@@ -39,6 +64,79 @@ type templateData struct {
 	Packages reportPackageList
 	// ProjectURL is the project's site on GitHub.
 	ProjectURL string
+	// HideSource is true if the source code of functions must not be rendered.
+	HideSource bool
+	// SkipEmptyFunctions is true if functions without any statement are left out of the
+	// function counts.
+	SkipEmptyFunctions bool
+	// HeadHTML is a raw, trusted, HTML snippet to render unescaped in the <head> of the page.
+	HeadHTML string
+	// BaseHref is the URL the report is hosted at, ending with a slash. Only
+	// prefix links to other pages with it: a <base> tag would break in-page links.
+	BaseHref string
+	// Preview is the Open Graph and Twitter Card metadata of the page. Is nil
+	// unless requested.
+	Preview *preview
+	// Focus lists the functions of all packages matching the focus option, sorted by
+	// coverage. Is nil if no focus is set.
+	Focus focusedFunctionList
+	// LargeFunctions lists the functions of all packages with more than
+	// LargeFunctionsThreshold statements, largest first. Is nil unless
+	// requested.
+	LargeFunctions focusedFunctionList
+	// LargeFunctionsThreshold is the number of statements above which a
+	// function is listed in LargeFunctions.
+	LargeFunctionsThreshold int
+	// Extensions is the coverage of statements by file extension, sorted by
+	// decreasing number of uncovered statements. Is nil unless requested.
+	Extensions []*extensionCoverage
+	// Owners is the coverage of statements by owner of the source files, sorted by
+	// decreasing number of uncovered statements. Is nil without CODEOWNERS file.
+	Owners []*ownerCoverage
+	// ThousandsSeparator is inserted between groups of thousands of counts.
+	ThousandsSeparator string
+	// Gauge is an inline SVG image of the total coverage, rendered unescaped.
+	// No gauge is rendered if empty.
+	Gauge string
+	// LastModified is true if the modification time of packages must be
+	// rendered, in sortable tables.
+	LastModified bool
+	// Minimap is true if long function sources must be rendered along with a
+	// minimap of their coverage.
+	Minimap bool
+	// Trend is true if the coverage of packages over the recorded runs is
+	// available, as the Trend sparkline of every package.
+	Trend bool
+	// LazySource is true if the source of functions must be rendered collapsed,
+	// from the JSON data of their LinesJSON method, the first time they are
+	// expanded.
+	LazySource bool
+	// LazySourceNoScript is true if the full source of functions must be
+	// rendered too when LazySource is set, for browsers without javascript.
+	LazySourceNoScript bool
+	// Tabs is true if packages must be rendered in tabs, the first tab being
+	// the overview. Sections are stacked when javascript is disabled.
+	Tabs bool
+	// Gate is the verdict of the coverage gate. Is nil if no gate rule is set.
+	Gate *gateResult
+}
+
+// Count formats a number of statements or functions, with thousands separators
+// if set.
+func (d *templateData) Count(n int) string {
+	return formatCount(n, d.ThousandsSeparator)
+}
+
+// packageSection is the data of the "package" template, rendering the section
+// of a single package. The fields of the template data are available too.
+type packageSection struct {
+	*templateData
+	Package *reportPackage
+}
+
+// Section returns the data of the "package" template for a package.
+func (d *templateData) Section(rp reportPackage) packageSection {
+	return packageSection{templateData: d, Package: &rp}
 }
 
 // StaticAssets sets all assets required for a theme.
@@ -51,18 +149,34 @@ type StaticAssets struct {
 var availableThemes = []Beautifier{
 	defaultTheme{},
 	kitTheme{},
+	highContrastTheme{},
 }
 
-// Theme to use for rendering.
-var curTheme Beautifier = defaultTheme{}
+var (
+	// themeMu guards availableThemes and the themes used for rendering.
+	themeMu sync.RWMutex
+	// Theme to use for rendering, chosen with Use. Is nil if none was chosen.
+	curTheme Beautifier
+	// Theme to use for rendering if none was chosen with Use.
+	fallbackTheme Beautifier = defaultTheme{}
+)
 
 // List returns all available themes.
 func List() []Beautifier {
-	return availableThemes
+	themeMu.RLock()
+	defer themeMu.RUnlock()
+	return append([]Beautifier(nil), availableThemes...)
 }
 
 // Get a theme by name. Returns nil if none found.
 func Get(name string) Beautifier {
+	themeMu.RLock()
+	defer themeMu.RUnlock()
+	return get(name)
+}
+
+// get returns the theme named name, or nil. themeMu must be held.
+func get(name string) Beautifier {
 	for _, t := range availableThemes {
 		if t.Name() == name {
 			return t
@@ -71,6 +185,23 @@ func Get(name string) Beautifier {
 	return nil
 }
 
+// Register makes a theme available for rendering, once validated. A custom
+// theme can be built by embedding one of the available themes and overriding
+// some of its methods, like Template(). Returns an error if the theme is
+// invalid or if its name is already taken.
+func Register(t Beautifier) error {
+	if err := Validate(t); err != nil {
+		return eris.Wrap(err, "register")
+	}
+	themeMu.Lock()
+	defer themeMu.Unlock()
+	if get(t.Name()) != nil {
+		return eris.Errorf("register: theme %q already exists", t.Name())
+	}
+	availableThemes = append(availableThemes, t)
+	return nil
+}
+
 // Use takes the name of the theme that will be used for rendering.
 // Returns an error for an unknown theme.
 func Use(name string) error {
@@ -78,11 +209,45 @@ func Use(name string) error {
 	if p == nil {
 		return eris.Errorf("unknown theme %q", name)
 	}
+	themeMu.Lock()
 	curTheme = p
+	themeMu.Unlock()
 	return nil
 }
 
-// Current returns the theme to use for rendering HTML.
+// SetDefaultTheme takes the name of the theme used for rendering when none
+// has been chosen with Use. Can be called from an init function of programs
+// embedding the package. Returns an error for an unknown theme.
+func SetDefaultTheme(name string) error {
+	p := Get(name)
+	if p == nil {
+		return eris.Errorf("unknown theme %q", name)
+	}
+	themeMu.Lock()
+	fallbackTheme = p
+	themeMu.Unlock()
+	return nil
+}
+
+// Current returns the theme to use for rendering HTML: the one chosen with
+// Use, or the default theme otherwise.
 func Current() Beautifier {
-	return curTheme
+	themeMu.RLock()
+	defer themeMu.RUnlock()
+	if curTheme != nil {
+		return curTheme
+	}
+	return fallbackTheme
+}
+
+// WriteStylesheet writes the default stylesheet of the theme to w, preceded
+// by a comment naming the theme. The output can be edited, then used as a
+// custom stylesheet with the Stylesheet option.
+func WriteStylesheet(w io.Writer, t Beautifier) error {
+	css, err := base64.StdEncoding.DecodeString(t.Data().Style)
+	if err != nil {
+		return eris.Wrap(err, "decode style")
+	}
+	_, err = fmt.Fprintf(w, "/* Default stylesheet of the %q theme of gocov-html. */\n%s", t.Name(), css)
+	return eris.Wrap(err, "write stylesheet")
 }
@@ -1,7 +1,10 @@
 package themes
 
 import (
+	"bytes"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -14,6 +17,7 @@ func TestGet(t *testing.T) {
 		{"empty string", "", nil},
 		{"unknown", "bad", nil},
 		{"default", "golang", defaultTheme{}},
+		{"high contrast", "high-contrast", highContrastTheme{}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -55,3 +59,46 @@ func TestList(t *testing.T) {
 		})
 	}
 }
+
+func TestSetDefaultTheme(t *testing.T) {
+	defer func() {
+		curTheme, fallbackTheme = nil, defaultTheme{}
+	}()
+	curTheme = nil
+	if err := SetDefaultTheme("bad"); err == nil {
+		t.Error("expected an error for an unknown theme")
+	}
+	if got := Current(); !reflect.DeepEqual(got, defaultTheme{}) {
+		t.Errorf("Current() = %v, want built-in default", got)
+	}
+	if err := SetDefaultTheme("kit"); err != nil {
+		t.Fatal(err)
+	}
+	if got := Current(); !reflect.DeepEqual(got, kitTheme{}) {
+		t.Errorf("Current() = %v, want kit theme", got)
+	}
+	if err := Use("golang"); err != nil {
+		t.Fatal(err)
+	}
+	if got := Current(); !reflect.DeepEqual(got, defaultTheme{}) {
+		t.Errorf("Current() = %v, want theme chosen with Use", got)
+	}
+}
+
+func TestWriteStylesheet(t *testing.T) {
+	for _, th := range List() {
+		t.Run(th.Name(), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteStylesheet(&buf, th); err != nil {
+				t.Fatal(err)
+			}
+			header := fmt.Sprintf("/* Default stylesheet of the %q theme of gocov-html. */\n", th.Name())
+			if !strings.HasPrefix(buf.String(), header) {
+				t.Errorf("missing header %q", header)
+			}
+			if !strings.Contains(buf.String(), "{") {
+				t.Errorf("got %q, want decoded CSS", buf.String())
+			}
+		})
+	}
+}
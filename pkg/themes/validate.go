@@ -0,0 +1,144 @@
+package themes
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/rotisserie/eris"
+)
+
+// rootTemplate is the name of the template rendering a whole page.
+const rootTemplate = "theme"
+
+// Validate checks that a theme is usable for rendering: its template must parse,
+// define the "theme" template and only reference fields of the template data
+// that exist. Fields are only checked where the dot is the template data itself,
+// or when using $. The optional "package" template is checked against the data
+// of a package section.
+func Validate(t Beautifier) (err error) {
+	if t.Name() == "" {
+		return eris.New("theme has no name")
+	}
+	defer func() {
+		// Template() usually relies on template.Must.
+		if r := recover(); r != nil {
+			err = eris.Errorf("theme %q: invalid template: %v", t.Name(), r)
+		}
+	}()
+	tmpl := t.Template()
+	if tmpl == nil {
+		return eris.Errorf("theme %q: missing template", t.Name())
+	}
+	root := tmpl.Lookup(rootTemplate)
+	if root == nil || root.Tree == nil {
+		return eris.Errorf("theme %q: no %q template defined", t.Name(), rootTemplate)
+	}
+	v := &validator{
+		tmpl:    tmpl,
+		typ:     reflect.TypeOf(&templateData{}),
+		visited: make(map[string]bool),
+	}
+	v.walkTemplate(root, true)
+	if section := tmpl.Lookup(sectionTemplate); section != nil && section.Tree != nil {
+		v.typ = reflect.TypeOf(&packageSection{})
+		v.walkTemplate(section, true)
+	}
+	if len(v.errs) > 0 {
+		return eris.Errorf("theme %q: %s", t.Name(), strings.Join(v.errs, "; "))
+	}
+	return nil
+}
+
+// validator walks the parse tree of a template to find references to unknown
+// fields of the template data.
+type validator struct {
+	tmpl *template.Template
+	// typ is the type of the template data.
+	typ     reflect.Type
+	visited map[string]bool
+	errs    []string
+}
+
+func (v *validator) walkTemplate(t *template.Template, rootDot bool) {
+	if v.visited[t.Name()] {
+		return
+	}
+	v.visited[t.Name()] = true
+	v.walk(t.Tree, t.Tree.Root, rootDot)
+}
+
+// checkField records an error if name is neither a field nor a method of the
+// template data.
+func (v *validator) checkField(tree *parse.Tree, n parse.Node, name string) {
+	if _, ok := v.typ.Elem().FieldByName(name); ok {
+		return
+	}
+	if _, ok := v.typ.MethodByName(name); ok {
+		return
+	}
+	loc, _ := tree.ErrorContext(n)
+	v.errs = append(v.errs, fmt.Sprintf("%s: unknown field %q", loc, name))
+}
+
+// walk checks the node. rootDot is true if the dot is the template data.
+func (v *validator) walk(tree *parse.Tree, node parse.Node, rootDot bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			v.walk(tree, c, rootDot)
+		}
+	case *parse.ActionNode:
+		v.walk(tree, n.Pipe, rootDot)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				v.walk(tree, arg, rootDot)
+			}
+		}
+	case *parse.FieldNode:
+		if rootDot {
+			v.checkField(tree, n, n.Ident[0])
+		}
+	case *parse.VariableNode:
+		if n.Ident[0] == "$" && len(n.Ident) > 1 {
+			v.checkField(tree, n, n.Ident[1])
+		}
+	case *parse.ChainNode:
+		v.walk(tree, n.Node, rootDot)
+	case *parse.IfNode:
+		v.walk(tree, n.Pipe, rootDot)
+		v.walk(tree, n.List, rootDot)
+		v.walk(tree, n.ElseList, rootDot)
+	case *parse.RangeNode:
+		v.walk(tree, n.Pipe, rootDot)
+		v.walk(tree, n.List, false)
+		v.walk(tree, n.ElseList, rootDot)
+	case *parse.WithNode:
+		v.walk(tree, n.Pipe, rootDot)
+		v.walk(tree, n.List, false)
+		v.walk(tree, n.ElseList, rootDot)
+	case *parse.TemplateNode:
+		v.walk(tree, n.Pipe, rootDot)
+		t := v.tmpl.Lookup(n.Name)
+		if t == nil || t.Tree == nil {
+			loc, _ := tree.ErrorContext(n)
+			v.errs = append(v.errs, fmt.Sprintf("%s: no template %q", loc, n.Name))
+			return
+		}
+		// Only follow templates called with the template data as dot.
+		if rootDot && n.Pipe != nil && len(n.Pipe.Cmds) == 1 && len(n.Pipe.Cmds[0].Args) == 1 {
+			if _, ok := n.Pipe.Cmds[0].Args[0].(*parse.DotNode); ok {
+				v.walkTemplate(t, true)
+			}
+		}
+	}
+}
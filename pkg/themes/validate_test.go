@@ -0,0 +1,131 @@
+package themes
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+)
+
+// brokenTheme is the default theme with a custom template.
+type brokenTheme struct {
+	Beautifier
+	name string
+	tmpl string
+}
+
+func (t brokenTheme) Name() string {
+	return t.name
+}
+
+func (t brokenTheme) Template() *template.Template {
+	return template.Must(template.New(rootTemplate).Parse(t.tmpl))
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		theme   Beautifier
+		wantErr string
+	}{
+		{"unknown field", brokenTheme{defaultTheme{}, "b", `{{define "theme"}}{{.When}}{{.Nope}}{{end}}`}, `unknown field "Nope"`},
+		{"unknown root field in range", brokenTheme{defaultTheme{}, "b", `{{define "theme"}}{{range .Packages}}{{.Pkg}}{{$.Nope}}{{end}}{{end}}`}, `unknown field "Nope"`},
+		{"unknown field in template", brokenTheme{defaultTheme{}, "b", `{{define "x"}}{{.Nope}}{{end}}{{define "theme"}}{{template "x" .}}{{end}}`}, `unknown field "Nope"`},
+		{"unknown field in package section", brokenTheme{defaultTheme{}, "b", `{{define "package"}}{{.Package.Pkg}}{{.Nope}}{{end}}{{define "theme"}}{{end}}`}, `unknown field "Nope"`},
+		{"valid package section", brokenTheme{defaultTheme{}, "b", `{{define "package"}}{{.Package.Pkg}}{{$.HideSource}}{{end}}{{define "theme"}}{{range $rp := .Packages}}{{template "package" $.Section $rp}}{{end}}{{end}}`}, ""},
+		{"missing template", brokenTheme{defaultTheme{}, "b", `{{define "theme"}}{{template "x" .}}{{end}}`}, `no template "x"`},
+		{"parse error", brokenTheme{defaultTheme{}, "b", `{{define "theme"}}{{if}}{{end}}`}, "invalid template"},
+		{"no name", brokenTheme{defaultTheme{}, "", `{{define "theme"}}{{end}}`}, "no name"},
+		{"valid", brokenTheme{defaultTheme{}, "b", `{{define "theme"}}{{range .Packages}}{{.Pkg.Name}}{{end}}{{.Overview.Pkg}}{{end}}`}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.theme)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAvailableThemes(t *testing.T) {
+	for _, th := range List() {
+		if err := Validate(th); err != nil {
+			t.Errorf("Validate(%q) = %v", th.Name(), err)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	defer func(ts []Beautifier) { availableThemes = ts }(availableThemes)
+
+	if err := Register(brokenTheme{defaultTheme{}, "broken", `{{define "theme"}}{{.Nope}}{{end}}`}); err == nil {
+		t.Error("broken theme registered")
+	}
+	if err := Register(brokenTheme{defaultTheme{}, "golang", `{{define "theme"}}{{end}}`}); err == nil {
+		t.Error("theme with duplicate name registered")
+	}
+	if err := Register(brokenTheme{defaultTheme{}, "custom", `{{define "theme"}}{{.When}}{{end}}`}); err != nil {
+		t.Fatal(err)
+	}
+	if Get("custom") == nil {
+		t.Error("custom theme not available")
+	}
+	if l := List(); len(l) > 0 {
+		l[0] = nil
+		if List()[0] == nil {
+			t.Error("List() shares the registered themes")
+		}
+	}
+
+	// Themes can be registered while others are looked up, run with -race.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("concurrent-%d", i)
+			if err := Register(brokenTheme{defaultTheme{}, name, `{{define "theme"}}{{.When}}{{end}}`}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for _, th := range List() {
+				Get(th.Name())
+			}
+		}()
+	}
+	wg.Wait()
+	for i := 0; i < 4; i++ {
+		if Get(fmt.Sprintf("concurrent-%d", i)) == nil {
+			t.Errorf("theme concurrent-%d not available", i)
+		}
+	}
+}
+
+func TestDataVersion(t *testing.T) {
+	defer func(old Beautifier) { curTheme = old }(curTheme)
+	curTheme = brokenTheme{defaultTheme{}, "custom", `{{define "theme"}}{{if ge .DataVersion 1}}v{{.DataVersion}}{{end}}{{end}}`}
+	if err := Validate(curTheme); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	data := `{"Packages":[{"Name":"p","Functions":[{"Name":"f","Statements":[{"Reached":1}]}]}]}`
+	opts := ReportOptions{CoverageMax: 100, MinHits: 1, HideSource: true}
+	if err := WriteReportCoverage(&buf, strings.NewReader(data), opts); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.TrimSpace(buf.String()), fmt.Sprintf("v%d", DataVersion); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}